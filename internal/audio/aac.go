@@ -0,0 +1,359 @@
+package audio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ADTS framing limits
+const (
+	adtsHeaderSize = 7    // Header without CRC
+	adtsMaxFrame   = 8191 // Largest frame_length
+	adtsSearch     = 64 << 10
+)
+
+// aacMaxFails is the number of undecodable frames in a row that end a stream
+const aacMaxFails = 50
+
+// aacDecoder decodes AAC-LC in ADTS frames, the framing of AAC radio streams
+// HE-AAC plays its AAC-LC core at the core sample rate: SBR and parametric
+// stereo are skipped, so such stations lack their upper frequencies
+type aacDecoder struct {
+	src      *bufio.Reader
+	header   adtsHeader          // First frame (sets sample rate)
+	frame    [adtsMaxFrame]byte  // Current frame
+	elements map[int]*aacElement // Channel state by element type and tag
+	mix      [][]float64         // Decoded channels of a raw data block
+	kinds    []int               // Element type of each mixed channel
+	out      []byte              // Pending output bytes
+	fails    int                 // Undecodable frames in a row
+}
+
+// adtsHeader is the fixed and variable header of an ADTS frame
+type adtsHeader struct {
+	profile   int  // Audio object type - 1 (1 is AAC-LC)
+	rateIndex int  // Sampling frequency index
+	protected bool // CRC follows the header
+	length    int  // Frame length including header
+	blocks    int  // Raw data blocks in frame
+}
+
+// parseADTS reads header at start of h (at least adtsHeaderSize bytes)
+func parseADTS(h []byte) (adtsHeader, bool) {
+	if h[0] != 0xFF || h[1]&0xF6 != 0xF0 {
+		return adtsHeader{}, false
+	}
+	hdr := adtsHeader{
+		protected: h[1]&1 == 0,
+		profile:   int(h[2] >> 6),
+		rateIndex: int(h[2] >> 2 & 0xF),
+		length:    int(h[3]&3)<<11 | int(h[4])<<3 | int(h[5]>>5),
+		blocks:    int(h[6] & 3),
+	}
+	if hdr.rateIndex >= len(aacSampleRates) || hdr.length < hdr.size() {
+		return adtsHeader{}, false
+	}
+	return hdr, true
+}
+
+// size returns header length in bytes
+func (h adtsHeader) size() int {
+	if h.protected {
+		return adtsHeaderSize + 2
+	}
+	return adtsHeaderSize
+}
+
+// newAACDecoder creates ADTS AAC-LC decoder
+func newAACDecoder(r io.Reader) (Decoder, error) {
+	d := &aacDecoder{
+		src:      bufio.NewReaderSize(r, 2*adtsMaxFrame),
+		elements: map[int]*aacElement{},
+	}
+	for skipped := 0; ; skipped++ {
+		head, err := d.src.Peek(adtsHeaderSize)
+		if err != nil {
+			return nil, err
+		}
+		if h, ok := parseADTS(head); ok {
+			d.header = h
+			break
+		}
+		if skipped == adtsSearch {
+			return nil, errors.New("no ADTS frame found")
+		}
+		d.src.Discard(1)
+	}
+	if d.header.profile != 1 {
+		return nil, fmt.Errorf("%w: AAC object type %d (only AAC-LC)", ErrUnsupportedCodec, d.header.profile+1)
+	}
+	return d, nil
+}
+
+// SampleRate returns rate of the first frame
+func (d *aacDecoder) SampleRate() int {
+	return aacSampleRates[d.header.rateIndex]
+}
+
+// Read returns decoded PCM
+func (d *aacDecoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		h, payload, err := d.nextFrame()
+		if err != nil {
+			return 0, err
+		}
+		if h.rateIndex != d.header.rateIndex || h.profile != d.header.profile {
+			continue // Another stream spliced in
+		}
+		if err := d.decodeFrame(h, payload); err != nil {
+			d.fails++
+			if d.fails >= aacMaxFails {
+				return 0, fmt.Errorf("aac: %w", err)
+			}
+			clear(d.elements) // Skip damaged frame
+			continue
+		}
+		d.fails = 0
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// nextFrame reads frame, skipping garbage up to the next ADTS header
+func (d *aacDecoder) nextFrame() (adtsHeader, []byte, error) {
+	for {
+		head, err := d.src.Peek(adtsHeaderSize)
+		if err != nil {
+			return adtsHeader{}, nil, err
+		}
+		h, ok := parseADTS(head)
+		if !ok {
+			d.src.Discard(1)
+			continue
+		}
+		frame := d.frame[:h.length]
+		if _, err := io.ReadFull(d.src, frame); err != nil {
+			return adtsHeader{}, nil, err
+		}
+		return h, frame[h.size():], nil
+	}
+}
+
+// decodeFrame decodes raw data blocks of a frame into d.out
+func (d *aacDecoder) decodeFrame(h adtsHeader, payload []byte) error {
+	b := &aacBits{buf: payload}
+	if h.protected && h.blocks > 0 {
+		b.skip(16 * h.blocks) // Raw data block positions
+	}
+	for i := 0; i <= h.blocks; i++ {
+		if err := d.decodeBlock(b, h.rateIndex); err != nil {
+			return err
+		}
+		if h.protected && h.blocks > 0 {
+			b.skip(16) // CRC of the block
+		}
+	}
+	return nil
+}
+
+// Syntactic elements of a raw data block
+const (
+	aacSCE = iota // Single channel
+	aacCPE        // Channel pair
+	aacCCE        // Coupling channel
+	aacLFE        // Low frequency effects channel
+	aacDSE        // Data stream
+	aacPCE        // Program config
+	aacFIL        // Fill (also carries SBR)
+	aacEND
+)
+
+// decodeBlock decodes a raw data block (1024 frames) and mixes it into d.out
+func (d *aacDecoder) decodeBlock(b *aacBits, rateIndex int) error {
+	d.mix, d.kinds = d.mix[:0], d.kinds[:0]
+	for {
+		kind := b.read(3)
+		if b.overrun() {
+			return errors.New("truncated raw data block")
+		}
+		switch kind {
+		case aacSCE, aacLFE:
+			el := d.element(kind, b.read(4))
+			if err := el.ch[0].parse(b, false, rateIndex); err != nil {
+				return err
+			}
+			el.ch[0].finish(rateIndex)
+			d.mix, d.kinds = append(d.mix, el.ch[0].pcm[:]), append(d.kinds, kind)
+		case aacCPE:
+			el := d.element(kind, b.read(4))
+			if err := el.parsePair(b, rateIndex); err != nil {
+				return err
+			}
+			d.mix = append(d.mix, el.ch[0].pcm[:], el.ch[1].pcm[:])
+			d.kinds = append(d.kinds, kind, kind)
+		case aacCCE:
+			return errors.New("coupling channels are not supported")
+		case aacDSE:
+			b.skip(4) // element_instance_tag
+			align := b.read(1) == 1
+			count := b.read(8)
+			if count == 255 {
+				count += b.read(8)
+			}
+			if align {
+				b.align()
+			}
+			b.skip(8 * count)
+		case aacPCE:
+			skipProgramConfig(b)
+		case aacFIL:
+			count := b.read(4)
+			if count == 15 {
+				count += b.read(8) - 1
+			}
+			b.skip(8 * count)
+		case aacEND:
+			b.align()
+			if b.overrun() {
+				return errors.New("truncated raw data block")
+			}
+			d.output()
+			return nil
+		}
+		if b.overrun() {
+			return errors.New("truncated raw data block")
+		}
+	}
+}
+
+// element returns state of element kind with tag
+func (d *aacDecoder) element(kind, tag int) *aacElement {
+	key := kind<<4 | tag
+	el, ok := d.elements[key]
+	if !ok {
+		el = &aacElement{}
+		el.ch[0].bank = newAACFilterbank()
+		if kind == aacCPE {
+			el.ch[1].bank = newAACFilterbank()
+		}
+		d.elements[key] = el
+	}
+	return el
+}
+
+// output downmixes decoded channels to stereo and appends them to d.out
+// A channel pair is front left and right; a single channel before it is
+// the center, further pairs are surround; LFE is dropped
+func (d *aacDecoder) output() {
+	var left, right, center []float64
+	var surround [][2][]float64
+	for i := 0; i < len(d.mix); i++ {
+		switch {
+		case d.kinds[i] == aacCPE && left == nil:
+			left, right = d.mix[i], d.mix[i+1]
+			i++
+		case d.kinds[i] == aacCPE:
+			surround = append(surround, [2][]float64{d.mix[i], d.mix[i+1]})
+			i++
+		case d.kinds[i] == aacSCE && center == nil:
+			center = d.mix[i]
+		}
+	}
+	if left == nil && center == nil {
+		return
+	}
+
+	const side = math.Sqrt2 / 2 // -3 dB
+	gain := 1.0
+	if left != nil && (center != nil || len(surround) > 0) {
+		gain = 1 / (1 + side*float64(len(surround)) + side*boolFloat(center != nil))
+	}
+	for i := 0; i < 1024; i++ {
+		var l, r float64
+		switch {
+		case left == nil:
+			l, r = center[i], center[i] // Mono
+		default:
+			l, r = left[i], right[i]
+			if center != nil {
+				l += side * center[i]
+				r += side * center[i]
+			}
+			for _, s := range surround {
+				l += side * s[0][i]
+				r += side * s[1][i]
+			}
+		}
+		d.out = samplesToBytes(d.out, []int16{clip(l * gain), clip(r * gain)})
+	}
+}
+
+// boolFloat returns 1 for true
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// skipProgramConfig reads over a program config element
+func skipProgramConfig(b *aacBits) {
+	b.skip(4 + 2 + 4) // Tag, object type, sampling frequency index
+	front, side, back := b.read(4), b.read(4), b.read(4)
+	lfe, assoc, cc := b.read(2), b.read(3), b.read(4)
+	for i := 0; i < 3; i++ { // Mono, stereo and matrix mixdown
+		if b.read(1) == 1 {
+			b.skip(4 - i/2) // Element number, or index and pseudo surround
+		}
+	}
+	b.skip(5*(front+side+back) + 4*(lfe+assoc) + 5*cc)
+	b.align()
+	b.skip(8 * b.read(8)) // Comment
+}
+
+// aacBits reads MSB-first bit fields of a frame
+// Reading past the end yields zeros and sets overrun
+type aacBits struct {
+	buf []byte
+	pos int // Bit position
+}
+
+// bit returns next bit
+func (b *aacBits) bit() int {
+	if b.pos >= len(b.buf)*8 {
+		b.pos++
+		return 0
+	}
+	v := int(b.buf[b.pos>>3]>>(7-b.pos&7)) & 1
+	b.pos++
+	return v
+}
+
+// read returns next n bits
+func (b *aacBits) read(n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		v = v<<1 | b.bit()
+	}
+	return v
+}
+
+// skip moves n bits forward
+func (b *aacBits) skip(n int) {
+	b.pos += n
+}
+
+// align moves to the next byte boundary
+func (b *aacBits) align() {
+	b.pos = (b.pos + 7) &^ 7
+}
+
+// overrun reports whether reads went past the end
+func (b *aacBits) overrun() bool {
+	return b.pos > len(b.buf)*8
+}
@@ -0,0 +1,219 @@
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Window shapes of AAC frames
+const (
+	aacSineWindow = 0
+	aacKBDWindow  = 1
+)
+
+// aacIMDCT is the inverse MDCT of n/2 coefficients into n samples,
+// computed as a DCT-IV through an n/8-point complex FFT
+type aacIMDCT struct {
+	n     int
+	pre   []complex128 // Twiddles before the FFT
+	post  []complex128 // Twiddles after the FFT
+	roots []complex128 // FFT roots of unity
+	rev   []int        // FFT bit reversal permutation
+	buf   []complex128
+	dct   []float64
+}
+
+// newAACIMDCT prepares inverse MDCT producing n samples (n a power of two)
+func newAACIMDCT(n int) *aacIMDCT {
+	m := n / 2    // DCT-IV length
+	size := m / 2 // FFT length
+	t := &aacIMDCT{
+		n:     n,
+		pre:   make([]complex128, size),
+		post:  make([]complex128, size),
+		roots: make([]complex128, size/2),
+		rev:   make([]int, size),
+		buf:   make([]complex128, size),
+		dct:   make([]float64, m),
+	}
+	for i := range t.pre {
+		t.pre[i] = cmplx.Exp(complex(0, -math.Pi*(float64(i)+0.25)/float64(m)))
+		t.post[i] = cmplx.Exp(complex(0, -math.Pi*float64(i)/float64(m)))
+	}
+	for i := range t.roots {
+		t.roots[i] = cmplx.Exp(complex(0, -2*math.Pi*float64(i)/float64(size)))
+	}
+	bits := 0
+	for 1<<bits < size {
+		bits++
+	}
+	for i := range t.rev {
+		r := 0
+		for b := 0; b < bits; b++ {
+			r |= (i >> b & 1) << (bits - 1 - b)
+		}
+		t.rev[i] = r
+	}
+	return t
+}
+
+// transform writes n samples of spec (n/2 coefficients) to out,
+// scaled by 2/n as the standard defines
+func (t *aacIMDCT) transform(spec, out []float64) {
+	m := t.n / 2
+	for i, r := range t.rev {
+		t.buf[r] = complex(spec[2*i], spec[m-1-2*i]) * t.pre[i]
+	}
+	t.fft()
+	for k, v := range t.buf {
+		v *= t.post[k]
+		t.dct[2*k] = real(v)
+		t.dct[m-1-2*k] = -imag(v)
+	}
+
+	// Unfold DCT-IV into the time domain
+	q := t.n / 4
+	scale := 2 / float64(t.n)
+	for i := 0; i < q; i++ {
+		out[i] = t.dct[q+i] * scale
+		out[3*q+i] = -t.dct[i] * scale
+	}
+	for i := q; i < 3*q; i++ {
+		out[i] = -t.dct[3*q-1-i] * scale
+	}
+}
+
+// fft transforms buf in place (input in bit reversed order)
+func (t *aacIMDCT) fft() {
+	size := len(t.buf)
+	for half := 1; half < size; half *= 2 {
+		step := size / (2 * half)
+		for start := 0; start < size; start += 2 * half {
+			for j := 0; j < half; j++ {
+				a, b := t.buf[start+j], t.buf[start+j+half]*t.roots[j*step]
+				t.buf[start+j], t.buf[start+j+half] = a+b, a-b
+			}
+		}
+	}
+}
+
+// aacWindows holds rising window halves by shape: sine and KBD
+type aacWindows struct {
+	long  [2][]float64 // 1024 values
+	short [2][]float64 // 128 values
+}
+
+// aacWindow are the windows shared by all decoders
+var aacWindow = aacWindows{
+	long:  [2][]float64{sineWindow(2048), kbdWindow(2048, 4)},
+	short: [2][]float64{sineWindow(256), kbdWindow(256, 6)},
+}
+
+// sineWindow returns rising half of sine window of length n
+func sineWindow(n int) []float64 {
+	w := make([]float64, n/2)
+	for i := range w {
+		w[i] = math.Sin(math.Pi / float64(n) * (float64(i) + 0.5))
+	}
+	return w
+}
+
+// kbdWindow returns rising half of Kaiser-Bessel-derived window of length n
+func kbdWindow(n int, alpha float64) []float64 {
+	half := n / 2
+	sums := make([]float64, half+1)
+	sum := 0.0
+	for i := 0; i <= half; i++ {
+		x := float64(2*i-half) / float64(half)
+		sum += besselI0(math.Pi * alpha * math.Sqrt(1-x*x))
+		sums[i] = sum
+	}
+	w := make([]float64, half)
+	for i := range w {
+		w[i] = math.Sqrt(sums[i] / sum)
+	}
+	return w
+}
+
+// besselI0 is the zeroth order modified Bessel function of the first kind
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; term > 1e-12*sum; k++ {
+		term *= (x / 2 / float64(k)) * (x / 2 / float64(k))
+		sum += term
+	}
+	return sum
+}
+
+// aacFilterbank turns spectra of one channel into PCM, overlapping
+// each frame with the second half of the previous one
+type aacFilterbank struct {
+	long    *aacIMDCT
+	short   *aacIMDCT
+	overlap [1024]float64
+	shape   int // Window shape of previous frame
+	buf     [2048]float64
+	win     [256]float64
+}
+
+// newAACFilterbank creates filterbank of a channel
+func newAACFilterbank() *aacFilterbank {
+	return &aacFilterbank{long: newAACIMDCT(2048), short: newAACIMDCT(256)}
+}
+
+// synthesize writes 1024 samples of frame with spectrum spec to out
+func (f *aacFilterbank) synthesize(info *aacICSInfo, spec *[1024]float64, out []float64) {
+	prevLong, curLong := aacWindow.long[f.shape], aacWindow.long[info.shape]
+	prevShort, curShort := aacWindow.short[f.shape], aacWindow.short[info.shape]
+	buf := f.buf[:]
+
+	if info.seq == aacEightShort {
+		clear(buf)
+		for w := 0; w < 8; w++ {
+			f.short.transform(spec[w*128:], f.win[:])
+			rise := curShort
+			if w == 0 {
+				rise = prevShort
+			}
+			at := 448 + w*128
+			for i := 0; i < 128; i++ {
+				buf[at+i] += f.win[i] * rise[i]
+				buf[at+128+i] += f.win[128+i] * curShort[127-i]
+			}
+		}
+	} else {
+		f.long.transform(spec[:], buf)
+		// Rising half
+		if info.seq == aacLongStop {
+			for i := 0; i < 448; i++ {
+				buf[i] = 0
+			}
+			for i := 0; i < 128; i++ {
+				buf[448+i] *= prevShort[i]
+			}
+		} else {
+			for i := 0; i < 1024; i++ {
+				buf[i] *= prevLong[i]
+			}
+		}
+		// Falling half
+		if info.seq == aacLongStart {
+			for i := 0; i < 128; i++ {
+				buf[1472+i] *= curShort[127-i]
+			}
+			for i := 1600; i < 2048; i++ {
+				buf[i] = 0
+			}
+		} else {
+			for i := 0; i < 1024; i++ {
+				buf[1024+i] *= curLong[1023-i]
+			}
+		}
+	}
+
+	for i := 0; i < 1024; i++ {
+		out[i] = buf[i] + f.overlap[i]
+	}
+	copy(f.overlap[:], buf[1024:])
+	f.shape = info.shape
+}
@@ -0,0 +1,583 @@
+package audio
+
+import (
+	"errors"
+	"math"
+)
+
+// Window sequences
+const (
+	aacOnlyLong = iota
+	aacLongStart
+	aacEightShort
+	aacLongStop
+)
+
+// Band types besides spectral codebooks 1-11
+const (
+	aacZeroBand       = 0
+	aacEscBook        = 11
+	aacNoiseBand      = 13 // Perceptual noise substitution
+	aacIntensityOut   = 14 // Intensity stereo, out of phase
+	aacIntensityBand  = 15 // Intensity stereo, in phase
+	aacMaxBands       = 64
+	aacScalefactorMid = 60 // Scalefactor codeword of difference 0
+)
+
+// Decoding errors of channel streams
+var (
+	errAACBitstream  = errors.New("damaged AAC bitstream")
+	errAACPrediction = errors.New("AAC prediction is not supported (only AAC-LC)")
+)
+
+// aacElement is state of a single channel or channel pair element
+type aacElement struct {
+	ch [2]aacChannel
+	ms [8][aacMaxBands]bool // Mid/side coded bands of a pair
+}
+
+// aacICSInfo is window and band layout of a channel frame
+type aacICSInfo struct {
+	seq      int // Window sequence
+	shape    int // Window shape
+	maxSFB   int // Transmitted bands
+	windows  int // 8 for short windows, else 1
+	groups   int // Window groups sharing scalefactors
+	groupLen [8]int
+	bands    []int // Band offsets within a window
+}
+
+// parse reads ics_info
+func (info *aacICSInfo) parse(b *aacBits, rateIndex int) error {
+	b.skip(1) // Reserved
+	info.seq = b.read(2)
+	info.shape = b.read(1)
+	info.groups, info.groupLen = 1, [8]int{1}
+	if info.seq == aacEightShort {
+		info.maxSFB = b.read(4)
+		grouping := b.read(7)
+		for i := 6; i >= 0; i-- {
+			if grouping>>i&1 == 1 {
+				info.groupLen[info.groups-1]++
+			} else {
+				info.groups++
+				info.groupLen[info.groups-1] = 1
+			}
+		}
+		info.windows = 8
+		info.bands = aacShortBands[rateIndex]
+	} else {
+		info.maxSFB = b.read(6)
+		if b.read(1) == 1 {
+			return errAACPrediction
+		}
+		info.windows = 1
+		info.bands = aacLongBands[rateIndex]
+	}
+	if info.maxSFB >= len(info.bands) {
+		return errAACBitstream
+	}
+	return nil
+}
+
+// aacTNSFilter is a temporal noise shaping filter
+type aacTNSFilter struct {
+	length int  // Bands covered
+	order  int  // Coefficients
+	down   bool // Filter runs downward in frequency
+	coef   [32]float64
+}
+
+// aacChannel is state and current frame of a channel
+type aacChannel struct {
+	info    aacICSInfo
+	gain    int                 // Global gain
+	book    [8][aacMaxBands]int // Band type by group and band
+	sf      [8][aacMaxBands]int // Scalefactor, noise energy or intensity position
+	pulses  [][2]int            // Pulse offsets and amplitudes
+	tns     [8][]aacTNSFilter   // TNS filters by window
+	filters [8][4]aacTNSFilter  // Storage of tns
+	quant   [1024]int           // Quantized spectrum
+	spec    [1024]float64       // Dequantized spectrum (short windows one after another)
+	pcm     [1024]float64       // Output of the frame
+	bank    *aacFilterbank
+	noise   uint32 // Random state of noise substitution
+}
+
+// parse reads individual_channel_stream and dequantizes its spectrum
+func (c *aacChannel) parse(b *aacBits, commonWindow bool, rateIndex int) error {
+	c.gain = b.read(8)
+	if !commonWindow {
+		if err := c.info.parse(b, rateIndex); err != nil {
+			return err
+		}
+	}
+	if err := c.parseSections(b); err != nil {
+		return err
+	}
+	if err := c.parseScalefactors(b); err != nil {
+		return err
+	}
+	c.pulses = c.pulses[:0]
+	if b.read(1) == 1 {
+		if err := c.parsePulses(b); err != nil {
+			return err
+		}
+	}
+	for w := range c.tns {
+		c.tns[w] = nil
+	}
+	if b.read(1) == 1 {
+		c.parseTNS(b)
+	}
+	if b.read(1) == 1 {
+		return errors.New("AAC gain control is not supported (only AAC-LC)")
+	}
+	if err := c.parseSpectrum(b); err != nil {
+		return err
+	}
+	if b.overrun() {
+		return errAACBitstream
+	}
+	c.dequantize()
+	return nil
+}
+
+// parseSections reads band types (section_data)
+func (c *aacChannel) parseSections(b *aacBits) error {
+	bits, esc := 5, 31
+	if c.info.windows == 8 {
+		bits, esc = 3, 7
+	}
+	for g := 0; g < c.info.groups; g++ {
+		for k := 0; k < c.info.maxSFB; {
+			book := b.read(4)
+			if book == 12 {
+				return errAACBitstream
+			}
+			n := 0
+			for {
+				incr := b.read(bits)
+				n += incr
+				if incr != esc {
+					break
+				}
+				if b.overrun() {
+					return errAACBitstream
+				}
+			}
+			if k+n > c.info.maxSFB || b.overrun() {
+				return errAACBitstream
+			}
+			for end := k + n; k < end; k++ {
+				c.book[g][k] = book
+			}
+		}
+	}
+	return nil
+}
+
+// parseScalefactors reads scalefactors, noise energies and intensity positions
+func (c *aacChannel) parseScalefactors(b *aacBits) error {
+	sf, noise, position := c.gain, c.gain-90, 0
+	firstNoise := true
+	for g := 0; g < c.info.groups; g++ {
+		for k := 0; k < c.info.maxSFB; k++ {
+			book := c.book[g][k]
+			if book == aacZeroBand {
+				c.sf[g][k] = 0
+				continue
+			}
+			if book == aacNoiseBand && firstNoise {
+				noise += b.read(9) - 256
+				c.sf[g][k] = noise
+				firstNoise = false
+				continue
+			}
+			delta := aacScalefactorTree.decode(b) - aacScalefactorMid
+			if delta < -aacScalefactorMid {
+				return errAACBitstream
+			}
+			switch book {
+			case aacIntensityOut, aacIntensityBand:
+				position += delta
+				c.sf[g][k] = position
+			case aacNoiseBand:
+				noise += delta
+				c.sf[g][k] = noise
+			default:
+				if sf += delta; sf < 0 || sf > 255 {
+					return errAACBitstream
+				}
+				c.sf[g][k] = sf
+			}
+		}
+	}
+	return nil
+}
+
+// parsePulses reads pulse_data (long windows only)
+func (c *aacChannel) parsePulses(b *aacBits) error {
+	if c.info.windows == 8 {
+		return errAACBitstream
+	}
+	n := b.read(2) + 1
+	start := b.read(6)
+	if start >= len(c.info.bands)-1 {
+		return errAACBitstream
+	}
+	k := c.info.bands[start]
+	for i := 0; i < n; i++ {
+		k += b.read(5)
+		if k >= 1024 {
+			return errAACBitstream
+		}
+		c.pulses = append(c.pulses, [2]int{k, b.read(4)})
+	}
+	return nil
+}
+
+// parseTNS reads tns_data
+func (c *aacChannel) parseTNS(b *aacBits) {
+	short := c.info.windows == 8
+	countBits, lengthBits, orderBits := 2, 6, 5
+	if short {
+		countBits, lengthBits, orderBits = 1, 4, 3
+	}
+	for w := 0; w < c.info.windows; w++ {
+		n := b.read(countBits)
+		if n == 0 {
+			continue
+		}
+		res := b.read(1) + 3 // Coefficient resolution in bits
+		c.tns[w] = c.filters[w][:n]
+		for i := range c.tns[w] {
+			f := &c.tns[w][i]
+			f.length = b.read(lengthBits)
+			f.order = b.read(orderBits)
+			if f.order == 0 {
+				continue
+			}
+			f.down = b.read(1) == 1
+			width := res - b.read(1) // Compressed coefficients drop a bit
+			for j := 0; j < f.order; j++ {
+				v := b.read(width)
+				if v >= 1<<(width-1) {
+					v -= 1 << width
+				}
+				f.coef[j] = tnsCoef(v, res)
+			}
+		}
+	}
+}
+
+// tnsCoef dequantizes reflection coefficient v of res bits
+func tnsCoef(v, res int) float64 {
+	step := float64(int(1)<<(res-1)) - 0.5
+	if v < 0 {
+		step += 1
+	}
+	return math.Sin(float64(v) / (step / (math.Pi / 2)))
+}
+
+// parseSpectrum reads quantized spectrum (spectral_data)
+func (c *aacChannel) parseSpectrum(b *aacBits) error {
+	clear(c.quant[:])
+	win := 0
+	for g := 0; g < c.info.groups; g++ {
+		for k := 0; k < c.info.maxSFB; k++ {
+			book := c.book[g][k]
+			if book == aacZeroBand || book > aacEscBook {
+				continue
+			}
+			cb, tree := &aacCodebooks[book-1], &aacTrees[book-1]
+			for w := win; w < win+c.info.groupLen[g]; w++ {
+				base := w * 128
+				for i := c.info.bands[k]; i < c.info.bands[k+1]; i += cb.dim {
+					if err := cb.decode(b, tree, c.quant[base+i:base+i+cb.dim]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		win += c.info.groupLen[g]
+	}
+	for _, p := range c.pulses {
+		if c.quant[p[0]] >= 0 {
+			c.quant[p[0]] += p[1]
+		} else {
+			c.quant[p[0]] -= p[1]
+		}
+	}
+	return nil
+}
+
+// decode reads a codeword with its signs and escapes into out
+func (cb *aacCodebook) decode(b *aacBits, tree *aacTree, out []int) error {
+	idx := tree.decode(b)
+	if idx < 0 {
+		return errAACBitstream
+	}
+	for i := cb.dim - 1; i >= 0; i-- {
+		out[i] = idx % cb.mod
+		idx /= cb.mod
+	}
+	if cb.signed {
+		for i := range out {
+			out[i] -= cb.mod / 2
+		}
+		return nil
+	}
+	for i := range out {
+		if out[i] != 0 && b.bit() == 1 {
+			out[i] = -out[i]
+		}
+	}
+	if cb.mod == 17 {
+		for i, v := range out {
+			if v != 16 && v != -16 {
+				continue
+			}
+			n := 4
+			for b.bit() == 1 {
+				if n++; n > 12 {
+					return errAACBitstream
+				}
+			}
+			esc := 1<<n + b.read(n)
+			if v < 0 {
+				esc = -esc
+			}
+			out[i] = esc
+		}
+	}
+	return nil
+}
+
+// aacPow43 is |q|^(4/3) of quantized values
+var aacPow43 = func() []float64 {
+	t := make([]float64, 8192)
+	for i := range t {
+		t[i] = math.Pow(float64(i), 4.0/3)
+	}
+	return t
+}()
+
+// dequantize scales quantized spectrum and fills noise bands
+func (c *aacChannel) dequantize() {
+	clear(c.spec[:])
+	win := 0
+	for g := 0; g < c.info.groups; g++ {
+		for k := 0; k < c.info.maxSFB; k++ {
+			book := c.book[g][k]
+			start, end := c.info.bands[k], c.info.bands[k+1]
+			for w := win; w < win+c.info.groupLen[g]; w++ {
+				band := c.spec[w*128+start : w*128+end]
+				switch {
+				case book == aacNoiseBand:
+					energy := 0.0
+					for i := range band {
+						c.noise = c.noise*1664525 + 1013904223
+						band[i] = float64(int32(c.noise))
+						energy += band[i] * band[i]
+					}
+					scale := math.Pow(2, 0.25*float64(c.sf[g][k])) / math.Sqrt(energy)
+					for i := range band {
+						band[i] *= scale
+					}
+				case book != aacZeroBand && book <= aacEscBook:
+					scale := math.Pow(2, 0.25*float64(c.sf[g][k]-100))
+					for i, q := range c.quant[w*128+start : w*128+end] {
+						v := q
+						if v < 0 {
+							v = -v
+						}
+						x := aacPow43[min(v, len(aacPow43)-1)] * scale
+						if q < 0 {
+							x = -x
+						}
+						band[i] = x
+					}
+				}
+			}
+		}
+		win += c.info.groupLen[g]
+	}
+}
+
+// finish applies TNS and the filterbank, producing c.pcm
+func (c *aacChannel) finish(rateIndex int) {
+	c.applyTNS(rateIndex)
+	c.bank.synthesize(&c.info, &c.spec, c.pcm[:])
+}
+
+// applyTNS filters spectrum of every window with its TNS filters
+func (c *aacChannel) applyTNS(rateIndex int) {
+	maxBands, maxOrder := aacTNSBands1024[rateIndex], 12
+	if c.info.windows == 8 {
+		maxBands, maxOrder = aacTNSBands128[rateIndex], 7
+	}
+	maxBands = min(maxBands, c.info.maxSFB)
+	var lpc [32]float64
+	for w := 0; w < c.info.windows; w++ {
+		spec := c.spec[w*128:]
+		top := len(c.info.bands) - 1
+		for _, f := range c.tns[w] {
+			bottom := max(top-f.length, 0)
+			order := min(f.order, maxOrder)
+			start, end := c.info.bands[min(bottom, maxBands)], c.info.bands[min(top, maxBands)]
+			top = bottom
+			if order == 0 || end <= start {
+				continue
+			}
+
+			// Reflection to LPC coefficients
+			lpc[0] = 1
+			for m := 1; m <= order; m++ {
+				var tmp [32]float64
+				for i := 1; i < m; i++ {
+					tmp[i] = lpc[i] + f.coef[m-1]*lpc[m-i]
+				}
+				copy(lpc[1:m], tmp[1:m])
+				lpc[m] = f.coef[m-1]
+			}
+
+			// All-pole filter along the bands
+			inc, at := 1, start
+			if f.down {
+				inc, at = -1, end-1
+			}
+			for n := 0; n < end-start; n, at = n+1, at+inc {
+				for i := 1; i <= min(n, order); i++ {
+					spec[at] -= lpc[i] * spec[at-i*inc]
+				}
+			}
+		}
+	}
+}
+
+// parsePair reads channel_pair_element and decodes both channels
+func (el *aacElement) parsePair(b *aacBits, rateIndex int) error {
+	left, right := &el.ch[0], &el.ch[1]
+	common := b.read(1) == 1
+	msPresent := 0
+	if common {
+		if err := left.info.parse(b, rateIndex); err != nil {
+			return err
+		}
+		right.info = left.info
+		msPresent = b.read(2)
+		for g := 0; g < left.info.groups; g++ {
+			for k := 0; k < left.info.maxSFB; k++ {
+				switch msPresent {
+				case 1:
+					el.ms[g][k] = b.read(1) == 1
+				case 2:
+					el.ms[g][k] = true
+				case 3:
+					return errAACBitstream
+				}
+			}
+		}
+	}
+	if err := left.parse(b, common, rateIndex); err != nil {
+		return err
+	}
+	if err := right.parse(b, common, rateIndex); err != nil {
+		return err
+	}
+
+	if common {
+		el.stereo(msPresent)
+	}
+	left.finish(rateIndex)
+	right.finish(rateIndex)
+	return nil
+}
+
+// stereo undoes mid/side and intensity coding of a common window pair
+func (el *aacElement) stereo(msPresent int) {
+	left, right := &el.ch[0], &el.ch[1]
+	info := &left.info
+	win := 0
+	for g := 0; g < info.groups; g++ {
+		for k := 0; k < info.maxSFB; k++ {
+			lb, rb := left.book[g][k], right.book[g][k]
+			start, end := info.bands[k], info.bands[k+1]
+			for w := win; w < win+info.groupLen[g]; w++ {
+				l, r := left.spec[w*128+start:w*128+end], right.spec[w*128+start:w*128+end]
+				switch {
+				case rb == aacIntensityBand || rb == aacIntensityOut:
+					scale := math.Pow(2, -0.25*float64(right.sf[g][k]))
+					if rb == aacIntensityOut {
+						scale = -scale
+					}
+					if msPresent == 1 && el.ms[g][k] {
+						scale = -scale
+					}
+					for i := range r {
+						r[i] = l[i] * scale
+					}
+				case msPresent > 0 && el.ms[g][k] && lb != aacNoiseBand && rb != aacNoiseBand:
+					for i := range l {
+						l[i], r[i] = l[i]+r[i], l[i]-r[i]
+					}
+				}
+			}
+		}
+		win += info.groupLen[g]
+	}
+}
+
+// aacTree decodes a Huffman code bit by bit
+// Node i has children at [i][0] and [i][1]: a positive value is the next
+// node, a negative one the symbol -v-1, zero a missing code
+type aacTree [][2]int32
+
+// newAACTree builds tree of codes with lengths bits, indexed by symbol
+func newAACTree[C uint16 | uint32](codes []C, bits []uint8) aacTree {
+	t := aacTree{{}}
+	for sym, code := range codes {
+		n := 0
+		for i := int(bits[sym]) - 1; i >= 0; i-- {
+			bit := int(code) >> i & 1
+			if i == 0 {
+				t[n][bit] = int32(-sym - 1)
+				break
+			}
+			if t[n][bit] == 0 {
+				t = append(t, [2]int32{})
+				t[n][bit] = int32(len(t) - 1)
+			}
+			n = int(t[n][bit])
+		}
+	}
+	return t
+}
+
+// decode returns next symbol (-1 for an invalid code)
+func (t aacTree) decode(b *aacBits) int {
+	n := 0
+	for !b.overrun() {
+		next := t[n][b.bit()]
+		switch {
+		case next < 0:
+			return int(-next - 1)
+		case next == 0:
+			return -1
+		}
+		n = int(next)
+	}
+	return -1
+}
+
+// Decoding trees of the codebooks
+var (
+	aacTrees = func() (trees [11]aacTree) {
+		for i, cb := range aacCodebooks {
+			trees[i] = newAACTree(cb.codes, cb.bits)
+		}
+		return trees
+	}()
+	aacScalefactorTree = newAACTree(aacScalefactorCodes[:], aacScalefactorBits[:])
+)
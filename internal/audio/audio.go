@@ -0,0 +1,53 @@
+// Package audio contains in-process audio pipeline: stream reader,
+// decoders, PCM mixer and output sinks
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Output PCM format (signed 16-bit little endian, interleaved stereo)
+const (
+	SampleRate = 44100 // Frames per second
+	Channels   = 2     // Stereo
+	FrameSize  = 4     // Bytes per stereo frame
+)
+
+// GainFromDB converts decibels string (e.g. "3") to linear gain
+func GainFromDB(db string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(db), 64)
+	if err != nil {
+		return 1
+	}
+	return math.Pow(10, v/20)
+}
+
+// bytesToSamples converts s16le bytes to samples, appending to dst
+func bytesToSamples(dst []int16, b []byte) []int16 {
+	for i := 0; i+1 < len(b); i += 2 {
+		dst = append(dst, int16(binary.LittleEndian.Uint16(b[i:])))
+	}
+	return dst
+}
+
+// samplesToBytes converts samples to s16le bytes, appending to dst
+func samplesToBytes(dst []byte, s []int16) []byte {
+	for _, v := range s {
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(v))
+	}
+	return dst
+}
+
+// clip converts mixed sample to int16 with saturation
+func clip(v float64) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
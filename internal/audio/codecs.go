@@ -0,0 +1,172 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/jfreymuth/oggvorbis"
+	"github.com/pion/opus"
+	"github.com/pion/opus/pkg/oggreader"
+)
+
+// Opus always decodes at 48 kHz
+const opusSampleRate = 48000
+
+// opusMaxFrame is the longest Opus packet (120 ms at 48 kHz)
+const opusMaxFrame = 5760
+
+// vorbisBuffer is the number of float samples decoded per Vorbis read
+const vorbisBuffer = 8192
+
+func init() {
+	RegisterDecoder("mp3", newMP3Decoder)
+	RegisterDecoder("aac", newAACDecoder)
+	RegisterDecoder("vorbis", newVorbisDecoder)
+	RegisterDecoder("opus", newOpusDecoder)
+}
+
+// newMP3Decoder creates MP3 decoder (output is always stereo s16le)
+func newMP3Decoder(r io.Reader) (Decoder, error) {
+	return mp3.NewDecoder(r)
+}
+
+// opusDecoder decodes Opus packets from Ogg container
+type opusDecoder struct {
+	ogg     *oggreader.OggReader
+	dec     opus.Decoder
+	pcm     []int16 // decoded packet
+	out     []byte  // pending output bytes
+	preSkip int     // samples to drop from stream start
+}
+
+// newOpusDecoder creates Ogg/Opus decoder
+func newOpusDecoder(r io.Reader) (Decoder, error) {
+	ogg, header, err := oggreader.NewWith(r)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := opus.NewDecoderWithOutput(opusSampleRate, Channels)
+	if err != nil {
+		return nil, err
+	}
+	return &opusDecoder{
+		ogg:     ogg,
+		dec:     dec,
+		pcm:     make([]int16, opusMaxFrame*Channels),
+		preSkip: int(header.PreSkip),
+	}, nil
+}
+
+// SampleRate returns Opus output rate
+func (d *opusDecoder) SampleRate() int {
+	return opusSampleRate
+}
+
+// Read returns decoded PCM
+func (d *opusDecoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		packet, _, err := d.ogg.ParseNextPacket()
+		if err != nil {
+			return 0, err
+		}
+		// Chained streams repeat headers on every track change
+		if bytes.HasPrefix(packet, []byte("OpusHead")) || bytes.HasPrefix(packet, []byte("OpusTags")) {
+			continue
+		}
+
+		n, err := d.dec.DecodeToInt16(packet, d.pcm)
+		if err != nil {
+			continue // Skip damaged packet
+		}
+		samples := d.pcm[:n*Channels]
+		if d.preSkip > 0 {
+			drop := min(d.preSkip, n)
+			samples = samples[drop*Channels:]
+			d.preSkip -= drop
+		}
+		d.out = samplesToBytes(d.out[:0], samples)
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// vorbisDecoder decodes Ogg/Vorbis, following chained streams
+type vorbisDecoder struct {
+	src  io.Reader
+	ogg  *oggvorbis.Reader
+	rate int       // sample rate of the first chain
+	pcm  []float32 // decoded interleaved samples
+	out  []byte    // pending output bytes
+}
+
+// newVorbisDecoder creates Ogg/Vorbis decoder
+func newVorbisDecoder(r io.Reader) (Decoder, error) {
+	ogg, err := oggvorbis.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &vorbisDecoder{
+		src:  r,
+		ogg:  ogg,
+		rate: ogg.SampleRate(),
+		pcm:  make([]float32, vorbisBuffer),
+	}, nil
+}
+
+// SampleRate returns rate of the first chain
+func (d *vorbisDecoder) SampleRate() int {
+	return d.rate
+}
+
+// Read returns decoded PCM
+func (d *vorbisDecoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		n, err := d.ogg.Read(d.pcm)
+		d.out = d.appendFrames(d.out[:0], d.pcm[:n])
+		switch {
+		case len(d.out) > 0:
+			// Play decoded samples, end of stream shows again on the next read
+		case err == io.EOF:
+			// Icecast starts a new chain with fresh headers on track change
+			if err := d.nextChain(); err != nil {
+				return 0, err
+			}
+		case err != nil:
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// nextChain opens the chained stream that follows the current one
+func (d *vorbisDecoder) nextChain() error {
+	ogg, err := oggvorbis.NewReader(d.src)
+	if err != nil {
+		return io.EOF
+	}
+	if ogg.SampleRate() != d.rate {
+		return fmt.Errorf("vorbis: sample rate changed from %d to %d", d.rate, ogg.SampleRate())
+	}
+	d.ogg = ogg
+	return nil
+}
+
+// appendFrames converts interleaved float samples to stereo s16le
+// Mono is duplicated; multichannel streams play their first two channels
+func (d *vorbisDecoder) appendFrames(dst []byte, pcm []float32) []byte {
+	channels := d.ogg.Channels()
+	right := min(1, channels-1)
+	for i := 0; i+channels <= len(pcm); i += channels {
+		l, r := float64(pcm[i])*math.MaxInt16, float64(pcm[i+right])*math.MaxInt16
+		dst = samplesToBytes(dst, []int16{clip(l), clip(r)})
+	}
+	return dst
+}
@@ -0,0 +1,106 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrUnsupportedCodec is returned when no decoder is registered for the codec
+var ErrUnsupportedCodec = errors.New("unsupported codec")
+
+// Decoder produces s16le interleaved stereo PCM
+type Decoder interface {
+	io.Reader
+	// SampleRate returns source sample rate
+	SampleRate() int
+}
+
+// DecoderFunc creates a decoder reading compressed audio from r
+type DecoderFunc func(r io.Reader) (Decoder, error)
+
+// decoders maps codec name to its decoder
+var decoders = map[string]DecoderFunc{}
+
+// RegisterDecoder adds decoder for codec name
+func RegisterDecoder(codec string, fn DecoderFunc) {
+	decoders[codec] = fn
+}
+
+// NewDecoder detects codec of r and returns a decoder producing PCM
+// at output SampleRate
+func NewDecoder(r io.Reader, contentType, location string) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, 16*1024)
+	head, _ := br.Peek(512)
+
+	codec := detectCodec(contentType, location, head)
+	fn, ok := decoders[codec]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCodec, codec)
+	}
+
+	dec, err := fn(br)
+	if err != nil {
+		return nil, fmt.Errorf("%s decoder: %w", codec, err)
+	}
+	if dec.SampleRate() == SampleRate {
+		return dec, nil
+	}
+	return newResampler(dec, dec.SampleRate()), nil
+}
+
+// detectCodec guesses codec from magic bytes, content type and file extension
+func detectCodec(contentType, location string, head []byte) string {
+	// Magic bytes are the most reliable
+	switch {
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return oggCodec(head)
+	case bytes.HasPrefix(head, []byte("ID3")):
+		return "mp3"
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xF6 == 0xF0:
+		return "aac" // ADTS sync word, layer 00
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		return "mp3"
+	}
+
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "mpeg"), strings.Contains(contentType, "mp3"):
+		return "mp3"
+	case strings.Contains(contentType, "aac"), strings.Contains(contentType, "mp4"):
+		return "aac"
+	case strings.Contains(contentType, "opus"):
+		return "opus"
+	case strings.Contains(contentType, "ogg"):
+		return oggCodec(head)
+	}
+
+	switch strings.ToLower(path.Ext(location)) {
+	case ".mp3":
+		return "mp3"
+	case ".aac", ".m4a":
+		return "aac"
+	case ".opus":
+		return "opus"
+	case ".ogg", ".oga":
+		return oggCodec(head)
+	}
+	return "unknown"
+}
+
+// oggCodec detects codec inside Ogg container by its identification header
+func oggCodec(head []byte) string {
+	switch {
+	case bytes.Contains(head, []byte("OpusHead")):
+		return "opus"
+	case bytes.Contains(head, []byte("\x01vorbis")):
+		return "vorbis"
+	case bytes.Contains(head, []byte("FLAC")):
+		return "flac"
+	}
+	return "ogg"
+}
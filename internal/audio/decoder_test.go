@@ -0,0 +1,75 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestDecoders(t *testing.T) {
+	tests := []struct {
+		file        string
+		contentType string
+		codec       string
+		rate        int // Source sample rate
+	}{
+		{"sample.mp3", "audio/mpeg", "mp3", 22050},
+		{"sample.aac", "audio/aac", "aac", 44100},
+		{"sample.ogg", "application/ogg", "vorbis", 44100},
+		{"sample.opus", "audio/ogg", "opus", 48000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.codec, func(t *testing.T) {
+			src, err := os.ReadFile("testdata/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := detectCodec(tt.contentType, tt.file, src); got != tt.codec {
+				t.Fatalf("detectCodec = %q, want %q", got, tt.codec)
+			}
+
+			dec, err := decoders[tt.codec](bytes.NewReader(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := dec.SampleRate(); got != tt.rate {
+				t.Errorf("SampleRate = %d, want %d", got, tt.rate)
+			}
+
+			pcm, err := NewDecoder(bytes.NewReader(src), tt.contentType, tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := io.ReadAll(pcm)
+			if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("decoding: %v", err)
+			}
+			if len(out) == 0 || len(out)%(2*Channels) != 0 {
+				t.Fatalf("decoded %d bytes, want whole stereo frames", len(out))
+			}
+			if peak := peak(out); peak < 100 {
+				t.Errorf("peak amplitude %d, want audible PCM", peak)
+			}
+		})
+	}
+}
+
+func TestDecoderUnsupported(t *testing.T) {
+	_, err := NewDecoder(bytes.NewReader([]byte("fLaC\x00\x00\x00\x22")), "audio/flac", "x.flac")
+	if !errors.Is(err, ErrUnsupportedCodec) {
+		t.Errorf("flac: error %v, want ErrUnsupportedCodec", err)
+	}
+}
+
+// peak returns largest absolute sample of s16le PCM
+func peak(pcm []byte) int {
+	m := 0
+	for i := 0; i+1 < len(pcm); i += 2 {
+		v := int(int16(binary.LittleEndian.Uint16(pcm[i:])))
+		m = max(m, v, -v)
+	}
+	return m
+}
@@ -0,0 +1,125 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+)
+
+// icyReader strips ICY metadata blocks from a Shoutcast/Icecast stream
+// Every metaint audio bytes the server inserts one length byte (×16)
+// followed by "StreamTitle='...';" text
+type icyReader struct {
+	r       io.ReadCloser
+	metaint int          // audio bytes between metadata blocks
+	left    int          // audio bytes left until next block
+	title   string       // last seen StreamTitle
+	onTitle func(string) // called when StreamTitle changes
+}
+
+// newICYReader wraps body with metadata interval metaint
+func newICYReader(body io.ReadCloser, metaint int, onTitle func(string)) *icyReader {
+	return &icyReader{
+		r:       body,
+		metaint: metaint,
+		left:    metaint,
+		onTitle: onTitle,
+	}
+}
+
+// Read returns audio bytes only
+func (ir *icyReader) Read(p []byte) (int, error) {
+	if ir.left == 0 {
+		if err := ir.readMeta(); err != nil {
+			return 0, err
+		}
+		ir.left = ir.metaint
+	}
+	if len(p) > ir.left {
+		p = p[:ir.left]
+	}
+	n, err := ir.r.Read(p)
+	ir.left -= n
+	return n, err
+}
+
+// Close closes underlying stream
+func (ir *icyReader) Close() error {
+	return ir.r.Close()
+}
+
+// readMeta reads one metadata block and reports title change
+func (ir *icyReader) readMeta() error {
+	var size [1]byte
+	if _, err := io.ReadFull(ir.r, size[:]); err != nil {
+		return err
+	}
+	if size[0] == 0 {
+		return nil // No change since previous block
+	}
+
+	block := make([]byte, int(size[0])*16)
+	if _, err := io.ReadFull(ir.r, block); err != nil {
+		return err
+	}
+
+	title, ok := parseStreamTitle(string(bytes.TrimRight(block, "\x00")))
+	if ok && title != ir.title {
+		ir.title = title
+		if ir.onTitle != nil {
+			ir.onTitle(title)
+		}
+	}
+	return nil
+}
+
+// parseStreamTitle extracts StreamTitle value from ICY metadata text
+// Example: StreamTitle='Artist - Title';StreamUrl='http://example.com';
+func parseStreamTitle(meta string) (string, bool) {
+	const key = "StreamTitle='"
+	start := strings.Index(meta, key)
+	if start == -1 {
+		return "", false
+	}
+	value := meta[start+len(key):]
+
+	// Titles may contain apostrophes, so look for the closing "';"
+	if end := strings.Index(value, "';"); end != -1 {
+		value = value[:end]
+	} else if end := strings.LastIndex(value, "'"); end != -1 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(value), true
+}
+
+// icyConn rewrites Shoutcast v1 "ICY 200 OK" status line to HTTP,
+// so net/http can parse the response
+type icyConn struct {
+	net.Conn
+	pending []byte // rewritten start of response
+	checked bool   // first read is done
+}
+
+// Read returns response with fixed status line
+func (c *icyConn) Read(p []byte) (int, error) {
+	if !c.checked {
+		c.checked = true
+		buf := make([]byte, 4096)
+		n, err := c.Conn.Read(buf)
+		buf = buf[:n]
+		if bytes.HasPrefix(buf, []byte("ICY ")) {
+			buf = append([]byte("HTTP/1.0 "), buf[4:]...)
+		}
+		c.pending = buf
+		if n == 0 {
+			return 0, err
+		}
+	}
+	if len(c.pending) > 0 {
+		n := copy(p, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
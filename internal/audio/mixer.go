@@ -0,0 +1,359 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crr/internal/logger"
+)
+
+// mixPeriod is how much audio the mixer produces per tick
+const mixPeriod = 20 * time.Millisecond
+
+// voiceBuffer is the number of decoded blocks buffered per voice (~2.3 s)
+const voiceBuffer = 100
+
+// blockSize is the size of a decoded PCM block in bytes (~23 ms)
+const blockSize = 4096
+
+// stopFade is the fade-out length used when a voice is stopped
+const stopFade = 30 * time.Millisecond
+
+// VoiceOptions configures a voice added to the mixer
+type VoiceOptions struct {
+	Gain   float64       // Linear gain (1 = unchanged)
+	FadeIn time.Duration // Fade-in length once voice starts sounding
+	// After is the voice to crossfade from: this one starts sounding
+	// FadeIn before After ends, while After fades out
+	After *Voice
+	// OnTitle is called when stream metadata title changes
+	OnTitle func(title string)
+}
+
+// Voice is a single sound source playing through the mixer
+type Voice struct {
+	opts    VoiceOptions
+	blocks  chan []int16  // decoded PCM blocks
+	pending []int16       // samples of current block not mixed yet
+	queued  atomic.Int64  // frames of blocks waiting to be mixed
+	decoded atomic.Bool   // all blocks are queued
+	gain    float64       // current gain (ramps to target)
+	target  float64       // target gain
+	step    float64       // gain change per frame
+	started bool          // first frames were mixed
+	sounds  chan struct{} // closed when first frames are mixed
+	cancel  context.CancelFunc
+	done    chan struct{}
+	err     error
+	mu      sync.Mutex
+}
+
+// Done returns channel closed when voice finishes
+func (v *Voice) Done() <-chan struct{} {
+	return v.done
+}
+
+// Err returns error that ended the voice (nil on normal end or stop)
+func (v *Voice) Err() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.err
+}
+
+// Sounding returns channel closed when voice starts producing sound
+func (v *Voice) Sounding() <-chan struct{} {
+	return v.sounds
+}
+
+// Started reports whether voice has produced sound
+func (v *Voice) Started() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.started
+}
+
+// remaining returns frames left to mix once decoding is over
+// (call with mixer mutex held)
+func (v *Voice) remaining() (int64, bool) {
+	if !v.decoded.Load() {
+		return 0, false
+	}
+	return v.queued.Load() + int64(len(v.pending)/Channels), true
+}
+
+// finished reports whether voice is over (call with mixer mutex held)
+func (v *Voice) finished() bool {
+	select {
+	case <-v.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Mixer sums voices and writes PCM to a sink in real time
+// It replaces the ffmpeg acrossfade filter graph
+type Mixer struct {
+	sink   Sink
+	voices []*Voice
+	volume float64
+	quit   chan struct{}
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewMixer creates a mixer writing to sink and starts it
+func NewMixer(sink Sink) *Mixer {
+	m := &Mixer{
+		sink:   sink,
+		volume: 1,
+		quit:   make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+// SetVolume sets master gain applied to all voices
+func (m *Mixer) SetVolume(gain float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volume = gain
+}
+
+// Play opens location and adds it as a new voice
+// Opening and decoding happen in background, errors are reported by Voice.Err
+func (m *Mixer) Play(location string, opts VoiceOptions) *Voice {
+	ctx, cancel := context.WithCancel(context.Background())
+	v := &Voice{
+		opts:   opts,
+		blocks: make(chan []int16, voiceBuffer),
+		sounds: make(chan struct{}),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.voices = append(m.voices, v)
+	m.mu.Unlock()
+
+	go m.decode(ctx, v, location)
+	return v
+}
+
+// Stop fades voice out and releases its stream
+func (m *Mixer) Stop(v *Voice) {
+	if v == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !v.started || v.gain == 0 {
+		m.finishLocked(v, nil)
+		return
+	}
+	v.target = 0
+	v.step = v.gain / (stopFade.Seconds() * SampleRate)
+}
+
+// Close stops all voices and closes the sink
+func (m *Mixer) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	for _, v := range m.voices {
+		m.finishLocked(v, nil)
+	}
+	m.voices = nil
+	close(m.quit)
+	m.mu.Unlock()
+	return m.sink.Close()
+}
+
+// decode reads location into voice blocks until end or cancel
+func (m *Mixer) decode(ctx context.Context, v *Voice, location string) {
+	stream, err := Open(ctx, location, v.opts.OnTitle)
+	if err != nil {
+		m.fail(v, err)
+		return
+	}
+	defer stream.Close()
+
+	pcm, err := NewDecoder(stream, stream.ContentType, location)
+	if err != nil {
+		m.fail(v, err)
+		return
+	}
+
+	buf := make([]byte, blockSize)
+	for {
+		n, err := io.ReadFull(pcm, buf)
+		if n >= FrameSize {
+			block := bytesToSamples(make([]int16, 0, n/2), buf[:n-n%FrameSize])
+			v.queued.Add(int64(len(block) / Channels))
+			select {
+			case v.blocks <- block:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				err = nil
+			}
+			if ctx.Err() != nil {
+				return // Stopped, not an error
+			}
+			if err != nil {
+				m.fail(v, err)
+				return
+			}
+			v.decoded.Store(true)
+			close(v.blocks)
+			return
+		}
+	}
+}
+
+// fail ends voice with error
+func (m *Mixer) fail(v *Voice, err error) {
+	logger.Log.Printf("audio: %v", err)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finishLocked(v, err)
+}
+
+// finishLocked ends voice and removes it from mixing (call with mutex held)
+func (m *Mixer) finishLocked(v *Voice, err error) {
+	if v.finished() {
+		return
+	}
+	v.mu.Lock()
+	v.err = err
+	v.mu.Unlock()
+	v.cancel()
+	close(v.done)
+	for i, other := range m.voices {
+		if other == v {
+			m.voices = append(m.voices[:i], m.voices[i+1:]...)
+			break
+		}
+	}
+}
+
+// run produces mixPeriod of audio on every tick
+func (m *Mixer) run() {
+	frames := int(SampleRate * mixPeriod.Seconds())
+	acc := make([]float64, frames*Channels)
+	out := make([]byte, 0, frames*FrameSize)
+
+	ticker := time.NewTicker(mixPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.quit:
+			return
+		case <-ticker.C:
+		}
+
+		for i := range acc {
+			acc[i] = 0
+		}
+		m.mix(acc)
+
+		out = out[:0]
+		for _, v := range acc {
+			out = binary.LittleEndian.AppendUint16(out, uint16(clip(v)))
+		}
+		if _, err := m.sink.Write(out); err != nil {
+			logger.Log.Printf("audio sink: %v", err)
+		}
+	}
+}
+
+// mix adds all sounding voices into acc
+func (m *Mixer) mix(acc []float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, v := range append([]*Voice(nil), m.voices...) {
+		// Wait for the tail of previous voice (e.g. chunk before stream)
+		if after := v.opts.After; after != nil && !after.finished() && !m.crossfadeLocked(after, v) {
+			continue
+		}
+		m.mixVoice(v, acc)
+	}
+
+	for i := range acc {
+		acc[i] *= m.volume
+	}
+}
+
+// crossfadeLocked reports whether voice to may start sounding over the end
+// of voice from, and then fades from out (call with mutex held)
+func (m *Mixer) crossfadeLocked(from, to *Voice) bool {
+	left, ok := from.remaining()
+	if !ok || float64(left) > to.opts.FadeIn.Seconds()*SampleRate {
+		return false
+	}
+	if from.started && from.target > 0 {
+		from.target = 0
+		from.step = from.gain / float64(max(left, 1))
+	}
+	return true
+}
+
+// mixVoice adds voice samples into acc (call with mutex held)
+func (m *Mixer) mixVoice(v *Voice, acc []float64) {
+	for i := 0; i < len(acc); i += Channels {
+		if len(v.pending) == 0 {
+			select {
+			case block, ok := <-v.blocks:
+				if !ok {
+					m.finishLocked(v, nil)
+					return
+				}
+				v.pending = block
+				v.queued.Add(-int64(len(block) / Channels))
+			default:
+				return // Underrun, wait for network
+			}
+		}
+
+		if !v.started {
+			v.mu.Lock()
+			v.started = true
+			v.mu.Unlock()
+			close(v.sounds)
+			v.target = v.opts.Gain
+			if v.opts.FadeIn > 0 {
+				v.step = v.target / (v.opts.FadeIn.Seconds() * SampleRate)
+			} else {
+				v.gain = v.target
+			}
+		}
+
+		// Ramp gain toward target
+		switch {
+		case v.gain < v.target:
+			v.gain = min(v.gain+v.step, v.target)
+		case v.gain > v.target:
+			v.gain = max(v.gain-v.step, v.target)
+			if v.gain == 0 {
+				m.finishLocked(v, nil)
+				return
+			}
+		}
+
+		for ch := 0; ch < Channels; ch++ {
+			acc[i+ch] += float64(v.pending[ch]) * v.gain
+		}
+		v.pending = v.pending[Channels:]
+	}
+}
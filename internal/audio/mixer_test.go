@@ -0,0 +1,77 @@
+package audio
+
+import (
+	"testing"
+	"time"
+)
+
+// constVoice returns decoded voice of frames with sample value
+func constVoice(frames int, value int16, opts VoiceOptions) *Voice {
+	v := &Voice{
+		opts:   opts,
+		blocks: make(chan []int16, 1),
+		sounds: make(chan struct{}),
+		cancel: func() {},
+		done:   make(chan struct{}),
+	}
+	block := make([]int16, frames*Channels)
+	for i := range block {
+		block[i] = value
+	}
+	v.blocks <- block
+	v.queued.Store(int64(frames))
+	v.decoded.Store(true)
+	close(v.blocks)
+	return v
+}
+
+func TestMixerCrossfade(t *testing.T) {
+	const second = SampleRate
+	intro := constVoice(second, 1000, VoiceOptions{Gain: 1})
+	stream := constVoice(3*second, 2000, VoiceOptions{Gain: 1, FadeIn: 500 * time.Millisecond, After: intro})
+	m := &Mixer{voices: []*Voice{intro, stream}, volume: 1}
+
+	// Mix in 10 ms periods, keeping the first sample of each
+	var out []float64
+	acc := make([]float64, SampleRate/100*Channels)
+	for range 200 {
+		clear(acc)
+		m.mix(acc)
+		out = append(out, acc[0])
+	}
+
+	tests := []struct {
+		at       time.Duration
+		min, max float64
+	}{
+		{100 * time.Millisecond, 1000, 1000},  // Intro alone
+		{400 * time.Millisecond, 1000, 1000},  // Before the crossfade
+		{600 * time.Millisecond, 1000, 2000},  // Both voices sounding
+		{750 * time.Millisecond, 1400, 1600},  // Halfway
+		{1100 * time.Millisecond, 2000, 2000}, // Stream alone
+	}
+	for _, tt := range tests {
+		got := out[tt.at/(10*time.Millisecond)]
+		if got < tt.min || got > tt.max || (tt.min < tt.max && (got == tt.min || got == tt.max)) {
+			t.Errorf("at %v: sample %.0f, want in [%.0f, %.0f]", tt.at, got, tt.min, tt.max)
+		}
+	}
+	if !intro.finished() {
+		t.Error("intro still playing after the crossfade")
+	}
+}
+
+func TestMixerStop(t *testing.T) {
+	v := constVoice(SampleRate, 1000, VoiceOptions{Gain: 1})
+	m := &Mixer{voices: []*Voice{v}, volume: 1}
+	acc := make([]float64, SampleRate/100*Channels)
+	m.mix(acc)
+	m.Stop(v)
+	for range 5 {
+		clear(acc)
+		m.mix(acc)
+	}
+	if !v.finished() || acc[len(acc)-2] != 0 {
+		t.Errorf("stopped voice: finished %v, last sample %.0f; want silence", v.finished(), acc[len(acc)-2])
+	}
+}
@@ -0,0 +1,74 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// resampler converts stereo s16le PCM to output SampleRate using linear interpolation
+type resampler struct {
+	src     *bufio.Reader
+	step    float64    // source frames per output frame
+	pos     float64    // position between cur and next frame (0..1)
+	cur     [2]float64 // current source frame
+	next    [2]float64 // next source frame
+	started bool
+	frame   [FrameSize]byte
+}
+
+// newResampler wraps src producing PCM at srcRate
+func newResampler(src io.Reader, srcRate int) *resampler {
+	return &resampler{
+		src:  bufio.NewReader(src),
+		step: float64(srcRate) / SampleRate,
+	}
+}
+
+// readFrame reads one stereo frame from source
+func (r *resampler) readFrame() ([2]float64, error) {
+	if _, err := io.ReadFull(r.src, r.frame[:]); err != nil {
+		return [2]float64{}, err
+	}
+	return [2]float64{
+		float64(int16(binary.LittleEndian.Uint16(r.frame[0:]))),
+		float64(int16(binary.LittleEndian.Uint16(r.frame[2:]))),
+	}, nil
+}
+
+// Read returns resampled PCM
+func (r *resampler) Read(p []byte) (int, error) {
+	if !r.started {
+		var err error
+		if r.cur, err = r.readFrame(); err != nil {
+			return 0, err
+		}
+		if r.next, err = r.readFrame(); err != nil {
+			return 0, err
+		}
+		r.started = true
+	}
+
+	n := 0
+	for n+FrameSize <= len(p) {
+		for r.pos >= 1 {
+			next, err := r.readFrame()
+			if err != nil {
+				if n > 0 {
+					return n, nil
+				}
+				return 0, err
+			}
+			r.cur, r.next = r.next, next
+			r.pos--
+		}
+
+		for ch := 0; ch < Channels; ch++ {
+			v := r.cur[ch] + (r.next[ch]-r.cur[ch])*r.pos
+			binary.LittleEndian.PutUint16(p[n+ch*2:], uint16(clip(v)))
+		}
+		n += FrameSize
+		r.pos += r.step
+	}
+	return n, nil
+}
@@ -0,0 +1,95 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"crr/internal/proc"
+)
+
+// Sink receives mixed PCM in output format
+type Sink interface {
+	io.WriteCloser
+}
+
+// players are PCM players that read raw s16le from stdin, in preference order
+var players = map[string][]string{
+	"pacat": {"--raw", "--format=s16le", "--rate=" + strconv.Itoa(SampleRate), "--channels=" + strconv.Itoa(Channels)},
+	"aplay": {"-q", "-t", "raw", "-f", "S16_LE", "-r", strconv.Itoa(SampleRate), "-c", strconv.Itoa(Channels)},
+	"play":  {"-q", "-t", "raw", "-r", strconv.Itoa(SampleRate), "-e", "signed", "-b", "16", "-c", strconv.Itoa(Channels), "-"},
+}
+
+// playerOrder is the order of auto-detection
+var playerOrder = []string{"pacat", "aplay", "play"}
+
+// OpenSink opens output sink described by spec:
+//
+//	"" or "auto"  first installed of pacat, aplay, play (sox)
+//	"pacat", ...  a specific PCM player
+//	"file:PATH"   raw PCM written to a file, FIFO or device
+func OpenSink(spec string) (Sink, error) {
+	spec = strings.TrimSpace(spec)
+	if path, ok := strings.CutPrefix(spec, "file:"); ok {
+		return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	}
+
+	if spec == "" || spec == "auto" {
+		spec = DetectSink()
+		if spec == "" {
+			return nil, fmt.Errorf("no PCM player found (install one of %s, or use file:PATH)",
+				strings.Join(playerOrder, ", "))
+		}
+	}
+
+	args, ok := players[spec]
+	if !ok {
+		return nil, fmt.Errorf("unknown audio sink %q", spec)
+	}
+	return newCommandSink(spec, args)
+}
+
+// DetectSink returns name of the first installed PCM player or empty string
+func DetectSink() string {
+	for _, name := range playerOrder {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// commandSink pipes PCM to stdin of a player process
+type commandSink struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+// newCommandSink starts player process
+func newCommandSink(name string, args []string) (*commandSink, error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := proc.Start(cmd); err != nil {
+		return nil, err
+	}
+	return &commandSink{cmd: cmd, stdin: stdin}, nil
+}
+
+// Write sends PCM to player
+func (s *commandSink) Write(p []byte) (int, error) {
+	return s.stdin.Write(p)
+}
+
+// Close stops player
+func (s *commandSink) Close() error {
+	s.stdin.Close()
+	proc.Kill(s.cmd)
+	proc.Wait(s.cmd)
+	return nil
+}
@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserAgent is sent with every stream request
+const UserAgent = "crr/1.0"
+
+// streamClient has no timeout, streams are endless
+// Its dialer understands Shoutcast v1 "ICY" responses
+var streamClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialer := &net.Dialer{Timeout: 15 * time.Second}
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &icyConn{Conn: conn}, nil
+		},
+		ResponseHeaderTimeout: 15 * time.Second,
+	},
+}
+
+// Stream is an opened audio source
+type Stream struct {
+	io.ReadCloser        // Audio bytes (ICY metadata already stripped)
+	ContentType   string // Content type (may be empty)
+	Name          string // Station name reported by server (icy-name)
+}
+
+// Open opens audio at location (http(s) URL or local file path)
+// If onTitle is set, ICY metadata is requested on the same connection
+// and onTitle is called every time StreamTitle changes
+func Open(ctx context.Context, location string, onTitle func(title string)) (*Stream, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		return &Stream{ReadCloser: f, ContentType: mime.TypeByExtension(filepath.Ext(location))}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	if onTitle != nil {
+		req.Header.Set("Icy-MetaData", "1")
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("stream %s: unexpected status %s", location, resp.Status)
+	}
+
+	s := &Stream{
+		ReadCloser:  resp.Body,
+		ContentType: resp.Header.Get("Content-Type"),
+		Name:        resp.Header.Get("icy-name"),
+	}
+	if metaint, _ := strconv.Atoi(resp.Header.Get("icy-metaint")); metaint > 0 && onTitle != nil {
+		s.ReadCloser = newICYReader(resp.Body, metaint, onTitle)
+	}
+	return s, nil
+}
@@ -0,0 +1,42 @@
+// Package cache stores data on disk under the XDG cache directory
+package cache
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Namespace separates cached lists of station providers other than
+// Radio Browser (empty: Radio Browser)
+var Namespace string
+
+// Dir returns crr cache directory ($XDG_CACHE_HOME/crr), creating it
+func Dir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "crr")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Path returns path of file name in cache directory
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// scoped returns file name within Namespace
+func scoped(name string) string {
+	if Namespace == "" {
+		return name
+	}
+	return url.PathEscape(Namespace) + "-" + name
+}
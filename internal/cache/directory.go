@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"crr/internal/data"
+	"crr/internal/fsutil"
+)
+
+// DirectoryTTL is how long cached country and tag lists are considered fresh
+var DirectoryTTL = 24 * time.Hour
+
+// directoryFile is the cache file name of country and tag lists
+const directoryFile = "directory.json"
+
+// directoryEntry is the on-disk format of cached lists
+type directoryEntry struct {
+	Saved     time.Time      `json:"saved"`
+	Directory data.Directory `json:"directory"`
+}
+
+// LoadDirectory returns cached country and tag lists
+// fresh is false when the entry is older than DirectoryTTL
+// Returns os.ErrNotExist error if nothing is cached
+func LoadDirectory() (dir data.Directory, fresh bool, err error) {
+	path, err := Path(scoped(directoryFile))
+	if err != nil {
+		return data.Directory{}, false, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return data.Directory{}, false, err
+	}
+
+	var entry directoryEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return data.Directory{}, false, err
+	}
+	return entry.Directory, time.Since(entry.Saved) < DirectoryTTL, nil
+}
+
+// SaveDirectory caches country and tag lists
+func SaveDirectory(dir data.Directory) error {
+	path, err := Path(scoped(directoryFile))
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(directoryEntry{Saved: time.Now(), Directory: dir})
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, b)
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"time"
+
+	"crr/internal/data"
+	"crr/internal/fsutil"
+)
+
+// ListingTTL is how long a downloaded station directory is considered fresh
+// (listed songs change, so it is shorter than StationsTTL)
+var ListingTTL = time.Hour
+
+// listingEntry is the on-disk format of a downloaded station directory
+type listingEntry struct {
+	Saved    time.Time      `json:"saved"`
+	Source   string         `json:"source"` // Directory URL
+	Stations []data.Station `json:"stations"`
+}
+
+// listingFile returns cache file name of directory downloaded from source
+func listingFile(source string) string {
+	return "listing-" + url.PathEscape(source) + ".json"
+}
+
+// LoadListing returns stations of directory downloaded from source URL
+// (Icecast yp.xml) with the time it was saved
+// Returns os.ErrNotExist error if nothing is cached
+func LoadListing(source string) (stations []data.Station, saved time.Time, err error) {
+	path, err := Path(listingFile(source))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var entry listingEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, time.Time{}, err
+	}
+	return entry.Stations, entry.Saved, nil
+}
+
+// SaveListing caches stations of directory downloaded from source URL
+func SaveListing(source string, stations []data.Station) error {
+	path, err := Path(listingFile(source))
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(listingEntry{Saved: time.Now(), Source: source, Stations: stations})
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, b)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"time"
+
+	"crr/internal/data"
+	"crr/internal/fsutil"
+)
+
+// StationsTTL is how long cached stations are considered fresh
+// Stale entries are still shown, but refreshed in background
+var StationsTTL = 6 * time.Hour
+
+// stationsEntry is the on-disk format of cached stations
+type stationsEntry struct {
+	Saved    time.Time      `json:"saved"`
+	Pages    int            `json:"pages,omitempty"` // Pages requested (0 if saved by an older version)
+	Stations []data.Station `json:"stations"`
+}
+
+// stationsFile returns cache file name for query key and filter key
+func stationsFile(query, filter string) string {
+	key := query
+	if filter != "" {
+		key += "_" + filter
+	}
+	return scoped("stations-" + url.PathEscape(key) + ".json")
+}
+
+// LoadStations returns cached stations for query key (see provider.StationQuery)
+// requested with filter key (empty for the default filter)
+// with the number of pages they were loaded in (0 if unknown)
+// fresh is false when the entry is older than StationsTTL
+// Returns os.ErrNotExist error if nothing is cached
+func LoadStations(query, filter string) (stations []data.Station, pages int, fresh bool, err error) {
+	path, err := Path(stationsFile(query, filter))
+	if err != nil {
+		return nil, 0, false, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	var entry stationsEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, 0, false, err
+	}
+	return entry.Stations, entry.Pages, time.Since(entry.Saved) < StationsTTL, nil
+}
+
+// SaveStations caches stations loaded in pages for query key and filter key
+func SaveStations(query, filter string, stations []data.Station, pages int) error {
+	path, err := Path(stationsFile(query, filter))
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(stationsEntry{Saved: time.Now(), Pages: pages, Stations: stations})
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(path, b)
+}
@@ -11,14 +11,9 @@ import (
 	rb "github.com/randomtoy/radiobrowser-go"
 )
 
-// Radio-browser base URL (one of the public mirrors).
-//
-// NOTE: for this mirror, HTTPS can intermittently stall while streaming larger
-// responses (e.g. limit=20), causing ctx deadline exceeded. Plain HTTP has been
-// observed to respond consistently fast in the same environment.
-var baseURL = "http://radio.telekost.ru"
-
-func GetStations(ctx context.Context, country, tag string) ([]data.Station, error) {
+// SearchStations returns a page of working stations matching opts
+// more reports whether the next page may have stations
+func SearchStations(ctx context.Context, opts rb.StationSearchOptions) (stations []data.Station, more bool, err error) {
 	// Safety net: library respects ctx, but our UI often uses Background().
 	// Keep a reasonable default deadline to avoid hanging forever.
 	if _, ok := ctx.Deadline(); !ok {
@@ -26,25 +21,48 @@ func GetStations(ctx context.Context, country, tag string) ([]data.Station, erro
 		ctx, cancel = context.WithTimeout(ctx, 90*time.Second)
 		defer cancel()
 	}
+	opts.HideBroken = true
 
-	country = strings.TrimSpace(country)
-	tag = strings.TrimSpace(tag)
-	if country == "" && tag == "" {
-		return nil, fmt.Errorf("country and tag are empty")
+	// Mirrors are tried in turn until one answers (see withMirror)
+	var rbStations []rb.Station
+	err = withMirror(ctx, func(ctx context.Context, base string) error {
+		var err error
+		rbStations, err = rb.StationSearch(ctx, base, opts)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
 	}
 
-	rbStations, err := rb.StationSearch(ctx, baseURL, rb.StationSearchOptions{
-		CountryCode: country,
-		Tag:         strings.ToLower(tag),
-		Limit:       20,
-		Order:       rb.StationOrderClickCount,
-		Reverse:     true,
-		HideBroken:  true,
+	// A full page (counted before skipping broken entries) means there may be more
+	return toStations(rbStations), opts.Limit > 0 && len(rbStations) == opts.Limit, nil
+}
+
+// GetStation returns station by UUID with its current stream URL
+func GetStation(ctx context.Context, uuid string) (data.Station, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+	var rbStations []rb.Station
+	err := withMirror(ctx, func(ctx context.Context, base string) error {
+		var err error
+		rbStations, err = rb.StationsByUUID(ctx, base, []string{uuid})
+		return err
 	})
 	if err != nil {
-		return nil, err
+		return data.Station{}, err
+	}
+	stations := toStations(rbStations)
+	if len(stations) == 0 {
+		return data.Station{}, fmt.Errorf("station %s not found", uuid)
 	}
+	return stations[0], nil
+}
 
+// toStations converts Radio Browser stations, skipping ones without name or stream
+func toStations(rbStations []rb.Station) []data.Station {
 	out := make([]data.Station, 0, len(rbStations))
 	for _, s := range rbStations {
 		link := strings.TrimSpace(s.URLResolved)
@@ -54,10 +72,45 @@ func GetStations(ctx context.Context, country, tag string) ([]data.Station, erro
 		if strings.TrimSpace(s.Name) == "" || link == "" {
 			continue
 		}
-		out = append(out, data.Station{
-			Name: s.Name,
-			Link: link,
-		})
+		out = append(out, toStation(s, link))
+	}
+	return out
+}
+
+// toStation converts Radio Browser station to our model
+func toStation(s rb.Station, link string) data.Station {
+	st := data.Station{
+		Name:        s.Name,
+		Link:        link,
+		UUID:        s.StationUUID,
+		Homepage:    strings.TrimSpace(s.Homepage),
+		Favicon:     strings.TrimSpace(s.Favicon),
+		Tags:        splitTags(s.Tags),
+		Language:    s.Language,
+		Codec:       s.Codec,
+		Bitrate:     s.Bitrate,
+		Country:     s.Country,
+		CountryCode: s.CountryCode,
+		State:       s.State,
+		GeoLat:      s.GeoLat,
+		GeoLong:     s.GeoLong,
+		Votes:       s.Votes,
+		Clicks:      s.ClickCount,
+		LastCheckOK: s.LastCheckOK == 1,
+	}
+	if t, err := time.Parse(time.RFC3339, s.LastCheckTimeISO); err == nil {
+		st.LastCheck = t
+	}
+	return st
+}
+
+// splitTags splits comma-separated tag list
+func splitTags(tags string) []string {
+	var out []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			out = append(out, tag)
+		}
 	}
-	return out, nil
+	return out
 }
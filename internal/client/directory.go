@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"crr/internal/data"
+
+	rb "github.com/randomtoy/radiobrowser-go"
+)
+
+// GetCountries returns countries with working stations, most stations first
+func GetCountries(ctx context.Context) ([]data.Category, error) {
+	var countries []rb.Country
+	err := withMirror(ctx, func(ctx context.Context, base string) error {
+		var err error
+		countries, err = rb.Countries(ctx, base, "", rb.CountriesOptions{
+			Order:      "stationcount",
+			Reverse:    true,
+			HideBroken: true,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []data.Category
+	for _, c := range countries {
+		code := strings.ToUpper(strings.TrimSpace(c.ISO3166_1))
+		if c.StationCount == 0 || code == "" || strings.TrimSpace(c.Name) == "" {
+			continue // Nothing to play, or not searchable by code
+		}
+		out = append(out, data.Category{Name: c.Name, Code: code, Stations: c.StationCount})
+	}
+	return out, nil
+}
+
+// GetTags returns up to limit tags with working stations, most stations first
+func GetTags(ctx context.Context, limit int) ([]data.Category, error) {
+	var tags []rb.Tag
+	err := withMirror(ctx, func(ctx context.Context, base string) error {
+		var err error
+		tags, err = rb.Tags(ctx, base, "", rb.TagsOptions{
+			Order:      "stationcount",
+			Reverse:    true,
+			HideBroken: true,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []data.Category
+	for _, t := range tags {
+		if len(out) == limit {
+			break
+		}
+		if t.StationCount == 0 || strings.TrimSpace(t.Name) == "" {
+			continue
+		}
+		out = append(out, data.Category{Name: t.Name, Stations: t.StationCount})
+	}
+	return out, nil
+}
+
+// GetLanguages returns languages with working stations, most stations first
+func GetLanguages(ctx context.Context) ([]data.Category, error) {
+	var languages []rb.Language
+	err := withMirror(ctx, func(ctx context.Context, base string) error {
+		var err error
+		languages, err = rb.Languages(ctx, base, "", rb.LanguagesOptions{
+			Order:      "stationcount",
+			Reverse:    true,
+			HideBroken: true,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []data.Category
+	for _, l := range languages {
+		if l.StationCount == 0 || strings.TrimSpace(l.Name) == "" {
+			continue
+		}
+		out = append(out, data.Category{Name: l.Name, Stations: l.StationCount})
+	}
+	return out, nil
+}
+
+// GetStates returns states and regions of country (by name) with working
+// stations, most stations first
+func GetStates(ctx context.Context, country string) ([]data.Category, error) {
+	country = strings.TrimSpace(country)
+	if country == "" {
+		return nil, fmt.Errorf("country is empty")
+	}
+	var states []rb.State
+	err := withMirror(ctx, func(ctx context.Context, base string) error {
+		var err error
+		states, err = rb.States(ctx, base, country, "", rb.StatesOptions{
+			Order:      "stationcount",
+			Reverse:    true,
+			HideBroken: true,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []data.Category
+	for _, s := range states {
+		if s.StationCount == 0 || strings.TrimSpace(s.Name) == "" || !strings.EqualFold(s.Country, country) {
+			continue // Search by name also matches longer country names
+		}
+		out = append(out, data.Category{Name: s.Name, Stations: s.StationCount})
+	}
+	return out, nil
+}
@@ -0,0 +1,266 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"crr/internal/cache"
+	"crr/internal/fsutil"
+	"crr/internal/logger"
+)
+
+// Mirrors is the configured list of Radio Browser mirrors (base URLs)
+// If empty, mirrors are discovered through DiscoveryURL
+var Mirrors []string
+
+// DiscoveryURL returns the list of all Radio Browser servers at /json/servers
+var DiscoveryURL = "https://all.api.radio-browser.info"
+
+// fallbackMirrors are used when discovery fails
+var fallbackMirrors = []string{
+	"https://de1.api.radio-browser.info",
+	"https://fi1.api.radio-browser.info",
+	"https://nl1.api.radio-browser.info",
+	"http://radio.telekost.ru",
+}
+
+// Mirror timeouts
+const (
+	MirrorProbeTimeout   = 3 * time.Second  // Latency probe of a single mirror
+	MirrorRequestTimeout = 20 * time.Second // One API request before failing over
+	discoveryTimeout     = 5 * time.Second  // Mirror list lookup
+)
+
+// userAgent is sent with mirror lookups
+const userAgent = "crr/1.0"
+
+// mirrorFile stores the last healthy mirror between runs
+const mirrorFile = "mirror"
+
+// mirrorPool keeps mirror candidates and the one that currently works
+type mirrorPool struct {
+	current string        // last mirror that answered
+	ranked  []string      // mirrors ordered by latency (nil until ranked)
+	ranking chan struct{} // closed when the ranking in flight ends (nil if none)
+	loaded  bool          // current was read from disk
+	mu      sync.Mutex
+}
+
+var pool = &mirrorPool{}
+
+// withMirror calls fn with mirror base URLs until one succeeds
+// The healthy mirror is tried first; on error or timeout the next fastest one
+func withMirror(ctx context.Context, fn func(ctx context.Context, base string) error) error {
+	var lastErr error
+	tried := map[string]bool{}
+
+	try := func(base string) bool {
+		tried[base] = true
+		attemptCtx, cancel := context.WithTimeout(ctx, MirrorRequestTimeout)
+		defer cancel()
+		err := fn(attemptCtx, base)
+		if err == nil {
+			pool.setCurrent(base)
+			return true
+		}
+		if ctx.Err() == nil {
+			logger.Log.Printf("Mirror %s failed: %v", base, err)
+		}
+		lastErr = err
+		return false
+	}
+
+	if base := pool.healthy(); base != "" && try(base) {
+		return nil
+	}
+	for _, base := range pool.candidates(ctx) {
+		if ctx.Err() != nil {
+			return ctx.Err() // Cancelled by caller, do not fail over
+		}
+		if !tried[base] && try(base) {
+			return nil
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no Radio Browser mirror available")
+	}
+	return lastErr
+}
+
+// healthy returns the mirror that worked last (also in a previous run)
+func (p *mirrorPool) healthy() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.loaded {
+		p.loaded = true
+		if path, err := cache.Path(mirrorFile); err == nil {
+			if b, err := os.ReadFile(path); err == nil {
+				p.current = strings.TrimSpace(string(b))
+			}
+		}
+		// A remembered mirror is only valid if it is still configured
+		if len(Mirrors) > 0 && !slices.Contains(Mirrors, p.current) {
+			p.current = ""
+		}
+	}
+	return p.current
+}
+
+// setCurrent remembers working mirror and saves it for the next run
+func (p *mirrorPool) setCurrent(base string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if base == p.current {
+		return
+	}
+	p.current = base
+	path, err := cache.Path(mirrorFile)
+	if err != nil {
+		return
+	}
+	if err := fsutil.WriteFile(path, []byte(base+"\n")); err != nil {
+		logger.Log.Printf("Save mirror: %v", err)
+	}
+}
+
+// candidates returns mirrors ordered by latency, discovering them once
+// Mirrors are probed without holding the lock; concurrent callers wait
+// for the ranking in flight instead of probing again
+func (p *mirrorPool) candidates(ctx context.Context) []string {
+	for {
+		p.mu.Lock()
+		ranked, wait := p.ranked, p.ranking
+		if ranked == nil && wait == nil {
+			p.ranking = make(chan struct{})
+		}
+		p.mu.Unlock()
+
+		switch {
+		case ranked != nil:
+			return ranked
+		case wait != nil:
+			select {
+			case <-wait:
+				continue // Ranked, or cancelled: then rank here
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		ranked = rankMirrors(ctx, discoverMirrors(ctx))
+		p.mu.Lock()
+		if ctx.Err() == nil {
+			p.ranked = ranked // Ranking of a cancelled request is not reliable
+		}
+		close(p.ranking)
+		p.ranking = nil
+		p.mu.Unlock()
+		return ranked
+	}
+}
+
+// discoverMirrors returns configured mirrors or looks them up
+func discoverMirrors(ctx context.Context) []string {
+	if len(Mirrors) > 0 {
+		return Mirrors
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	var servers []struct {
+		Name string `json:"name"`
+	}
+	if err := getJSON(ctx, DiscoveryURL+"/json/servers", &servers); err != nil {
+		logger.Log.Printf("Mirror discovery: %v", err)
+		return fallbackMirrors
+	}
+
+	var out []string
+	for _, s := range servers {
+		base := "https://" + strings.TrimSpace(s.Name)
+		if s.Name != "" && !slices.Contains(out, base) {
+			out = append(out, base)
+		}
+	}
+	if len(out) == 0 {
+		return fallbackMirrors
+	}
+	return out
+}
+
+// rankMirrors probes mirrors concurrently and orders them by latency
+// Mirrors that did not answer are kept at the end (in original order)
+func rankMirrors(ctx context.Context, mirrors []string) []string {
+	latency := make([]time.Duration, len(mirrors))
+	var wg sync.WaitGroup
+	for i, base := range mirrors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			latency[i] = probeMirror(ctx, base)
+		}()
+	}
+	wg.Wait()
+
+	order := make([]int, len(mirrors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return latency[order[a]] < latency[order[b]]
+	})
+
+	out := make([]string, len(mirrors))
+	for i, idx := range order {
+		out[i] = mirrors[idx]
+		logger.Log.Printf("Mirror %s: %v", mirrors[idx], latency[idx])
+	}
+	return out
+}
+
+// probeMirror returns response time of mirror stats endpoint
+// Unreachable mirrors get the maximum duration
+func probeMirror(ctx context.Context, base string) time.Duration {
+	ctx, cancel := context.WithTimeout(ctx, MirrorProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var stats map[string]any
+	if err := getJSON(ctx, strings.TrimRight(base, "/")+"/json/stats", &stats); err != nil {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Since(start)
+}
+
+// getJSON fetches url and decodes JSON response into out
+func getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: unexpected status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
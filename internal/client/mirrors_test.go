@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"crr/internal/cache"
+)
+
+// useMirrors configures mirrors with a fresh pool and cache directory
+func useMirrors(t *testing.T, mirrors ...string) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	saved, savedPool := Mirrors, pool
+	Mirrors, pool = mirrors, &mirrorPool{}
+	t.Cleanup(func() { Mirrors, pool = saved, savedPool })
+}
+
+// rememberMirror saves base as the mirror that worked in a previous run
+func rememberMirror(t *testing.T, base string) {
+	t.Helper()
+	path, err := cache.Path(mirrorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(base+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stubMirror serves stats and one country, or fails every request if broken
+func stubMirror(t *testing.T, broken bool, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits != nil {
+			hits.Add(1)
+		}
+		if broken {
+			http.Error(w, "down", http.StatusInternalServerError)
+			return
+		}
+		switch r.URL.Path {
+		case "/json/stats":
+			w.Write([]byte(`{"stations":"1"}`))
+		case "/json/countries":
+			w.Write([]byte(`[{"name":"Germany","iso_3166_1":"DE","stationcount":"5"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestMirrorFailover(t *testing.T) {
+	var brokenHits atomic.Int32
+	broken := stubMirror(t, true, &brokenHits)
+	good := stubMirror(t, false, nil)
+	useMirrors(t, broken.URL, good.URL)
+	rememberMirror(t, broken.URL)
+
+	countries, err := GetCountries(context.Background())
+	if err != nil {
+		t.Fatalf("no failover: %v", err)
+	}
+	if len(countries) != 1 || countries[0].Code != "DE" {
+		t.Errorf("countries = %+v, want Germany from the working mirror", countries)
+	}
+	if brokenHits.Load() == 0 {
+		t.Error("remembered mirror was not tried first")
+	}
+	if got := pool.healthy(); got != good.URL {
+		t.Errorf("healthy mirror = %q, want %q", got, good.URL)
+	}
+
+	// The working mirror is remembered for the next run
+	path, _ := cache.Path(mirrorFile)
+	if b, err := os.ReadFile(path); err != nil || strings.TrimSpace(string(b)) != good.URL {
+		t.Errorf("saved mirror = %q, %v; want %q", b, err, good.URL)
+	}
+}
+
+func TestMirrorFailoverAllBroken(t *testing.T) {
+	a := stubMirror(t, true, nil)
+	b := stubMirror(t, true, nil)
+	useMirrors(t, a.URL, b.URL)
+
+	if _, err := GetCountries(context.Background()); err == nil {
+		t.Error("no error when every mirror fails")
+	}
+}
+
+func TestMirrorRankingUnlocked(t *testing.T) {
+	var probes atomic.Int32
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	var once, released sync.Once
+	unblock := func() { released.Do(func() { close(release) }) }
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		once.Do(func() { close(probing) })
+		<-release
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(unblock) // Before Close, which waits for the probe
+	useMirrors(t, slow.URL)
+
+	var wg sync.WaitGroup
+	results := make([][]string, 4)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = pool.candidates(context.Background())
+		}()
+	}
+
+	// Other requests are not held up by the probe in flight
+	<-probing
+	done := make(chan struct{})
+	go func() {
+		pool.setCurrent(slow.URL)
+		pool.healthy()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("mirror pool locked while probing")
+	}
+
+	unblock()
+	wg.Wait()
+	if n := probes.Load(); n != 1 {
+		t.Errorf("mirror probed %d times by concurrent requests, want once", n)
+	}
+	for i, r := range results {
+		if len(r) != 1 || r[0] != slow.URL {
+			t.Errorf("candidates %d = %q, want [%s]", i, r, slow.URL)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+// Package config loads runtime settings from the YAML config file
+// ($XDG_CONFIG_HOME/crr/config.yaml), environment and command line
+// Later sources override earlier ones: defaults, file, CRR_* env, flags
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds runtime settings
+type Config struct {
+	Backend        string        `yaml:"backend"`         // Audio backend (empty or auto: detect)
+	Sink           string        `yaml:"sink"`            // Native backend output (empty or auto: detect)
+	Mirrors        []string      `yaml:"mirrors"`         // Radio Browser mirrors (empty: discover)
+	StationLimit   int           `yaml:"station_limit"`   // Stations per country/genre request
+	StationTTL     time.Duration `yaml:"station_ttl"`     // Station cache freshness
+	DirectoryTTL   time.Duration `yaml:"directory_ttl"`   // Country and tag list cache freshness
+	DirectoryOrder string        `yaml:"directory_order"` // Country and tag order (popularity, name)
+	TagLimit       int           `yaml:"tag_limit"`       // Tags offered in the Genre drum
+	FetchDebounce  time.Duration `yaml:"fetch_debounce"`  // Delay before station request
+	MarqueeTick    time.Duration `yaml:"marquee_tick"`    // Marquee animation step
+	ChunkVolumeDB  float64       `yaml:"chunk_volume_db"` // Chunk loudness relative to stream
+	VisibleItems   int           `yaml:"visible_items"`   // Items shown in a drum (odd)
+	ActiveColor    string        `yaml:"active_color"`    // Color of selection (ANSI 0-255 or #RRGGBB)
+	InactiveColor  string        `yaml:"inactive_color"`  // Color of other items
+	LogFile        string        `yaml:"log_file"`        // Log path (empty disables logging)
+
+	// Columns lists category drums left of Station drum, e.g. [language, tag]
+	// (continent, country, state, language, tag)
+	Columns []string `yaml:"columns"`
+
+	// Providers lists station directories merged into the drums, e.g.
+	// [radiobrowser, icecast]; a stream listed twice keeps the first entry
+	Providers  []string      `yaml:"providers"`
+	IcecastURL string        `yaml:"icecast_url"` // Icecast directory (yp.xml)
+	IcecastTTL time.Duration `yaml:"icecast_ttl"` // Icecast directory cache freshness
+
+	// Keys remaps actions to keys, e.g. "move-up: [up, k]" or "quit: ctrl+q"
+	// Keys of a chord are separated by spaces ("g g")
+	Keys map[string]KeyList `yaml:"keys"`
+}
+
+// KeyList is a list of keys; a single key may be written as a plain string
+type KeyList []string
+
+// UnmarshalYAML accepts a string or a list of strings
+func (k *KeyList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*k = KeyList{node.Value}
+		return nil
+	}
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return err
+	}
+	*k = keys
+	return nil
+}
+
+// KeyOverrides returns key remaps as plain lists
+func (c *Config) KeyOverrides() map[string][]string {
+	out := make(map[string][]string, len(c.Keys))
+	for action, keys := range c.Keys {
+		out[action] = keys
+	}
+	return out
+}
+
+// Default returns built-in settings
+func Default() Config {
+	return Config{
+		StationLimit:   20,
+		StationTTL:     6 * time.Hour,
+		DirectoryTTL:   24 * time.Hour,
+		DirectoryOrder: "popularity",
+		TagLimit:       200,
+		FetchDebounce:  3 * time.Second,
+		MarqueeTick:    300 * time.Millisecond,
+		ChunkVolumeDB:  3,
+		VisibleItems:   5,
+		ActiveColor:    "212",
+		InactiveColor:  "240",
+		LogFile:        filepath.Join(os.TempDir(), "crr.log"),
+		Columns:        []string{"country", "tag"},
+		Providers:      []string{"radiobrowser"},
+		IcecastURL:     "https://dir.xiph.org/yp.xml",
+		IcecastTTL:     time.Hour,
+	}
+}
+
+// Path returns default config file path
+func Path() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.yaml"
+	}
+	return filepath.Join(dir, "crr", "config.yaml")
+}
+
+// LoadFile reads settings from path on top of c
+// A missing file is not an error; unknown keys are
+func (c *Config) LoadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Setting describes one key that can be set from env and flags
+type Setting struct {
+	Key   string                          // Config key (also flag name)
+	Usage string                          // Flag help
+	set   func(c *Config, v string) error // Parses and stores value
+}
+
+// Env returns environment variable name of setting (CRR_STATION_LIMIT)
+func (s Setting) Env() string {
+	return "CRR_" + strings.ToUpper(strings.ReplaceAll(s.Key, "-", "_"))
+}
+
+// Settings lists overridable settings
+var Settings = []Setting{
+	{"backend", "audio backend (auto, mpv, ffplay, native, null)", func(c *Config, v string) error {
+		c.Backend = v
+		return nil
+	}},
+	{"sink", "native backend output (auto, pacat, aplay, play, file:PATH)", func(c *Config, v string) error {
+		c.Sink = v
+		return nil
+	}},
+	{"mirrors", "comma-separated Radio Browser mirrors (default: discover)", func(c *Config, v string) error {
+		c.Mirrors = splitList(v)
+		return nil
+	}},
+	{"columns", "comma-separated drums left of Station (continent, country, state, language, tag)", func(c *Config, v string) error {
+		c.Columns = splitList(v)
+		return nil
+	}},
+	{"providers", "comma-separated station directories (radiobrowser, icecast)", func(c *Config, v string) error {
+		c.Providers = splitList(v)
+		return nil
+	}},
+	{"icecast-url", "Icecast directory URL (yp.xml)", func(c *Config, v string) error {
+		c.IcecastURL = v
+		return nil
+	}},
+	{"icecast-ttl", "Icecast directory cache freshness (e.g. 1h)", durationSetter(func(c *Config) *time.Duration { return &c.IcecastTTL })},
+	{"station-limit", "stations per request", intSetter(func(c *Config) *int { return &c.StationLimit })},
+	{"station-ttl", "station cache freshness (e.g. 6h)", durationSetter(func(c *Config) *time.Duration { return &c.StationTTL })},
+	{"directory-ttl", "country and tag list cache freshness (e.g. 24h)", durationSetter(func(c *Config) *time.Duration { return &c.DirectoryTTL })},
+	{"directory-order", "country and tag order (popularity, name)", func(c *Config, v string) error {
+		c.DirectoryOrder = v
+		return nil
+	}},
+	{"tag-limit", "tags offered in the Genre drum", intSetter(func(c *Config) *int { return &c.TagLimit })},
+	{"fetch-debounce", "delay before station request (e.g. 3s)", durationSetter(func(c *Config) *time.Duration { return &c.FetchDebounce })},
+	{"marquee-tick", "marquee animation step (e.g. 300ms)", durationSetter(func(c *Config) *time.Duration { return &c.MarqueeTick })},
+	{"chunk-volume-db", "chunk loudness relative to stream in dB", func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("not a number: %q", v)
+		}
+		c.ChunkVolumeDB = f
+		return nil
+	}},
+	{"visible-items", "items shown in a drum (odd number)", intSetter(func(c *Config) *int { return &c.VisibleItems })},
+	{"active-color", "selection color (ANSI 0-255 or #RRGGBB)", func(c *Config, v string) error {
+		c.ActiveColor = v
+		return nil
+	}},
+	{"inactive-color", "color of other items (ANSI 0-255 or #RRGGBB)", func(c *Config, v string) error {
+		c.InactiveColor = v
+		return nil
+	}},
+	{"log-file", "log file path (empty disables logging)", func(c *Config, v string) error {
+		c.LogFile = v
+		return nil
+	}},
+}
+
+// Set parses value of setting key
+func (c *Config) Set(key, value string) error {
+	for _, s := range Settings {
+		if s.Key == key {
+			if err := s.set(c, strings.TrimSpace(value)); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown setting %q", key)
+}
+
+// LoadEnv applies CRR_* environment variables
+func (c *Config) LoadEnv() error {
+	var errs []error
+	for _, s := range Settings {
+		if v, ok := os.LookupEnv(s.Env()); ok {
+			if err := c.Set(s.Key, v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.Env(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// colorPattern matches ANSI 256 color numbers and hex colors
+var colorPattern = regexp.MustCompile(`^(\d{1,3}|#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3})$`)
+
+// Validate checks values and reports all problems at once
+func (c *Config) Validate() error {
+	var errs []error
+	bad := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+	}
+
+	if c.StationLimit < 1 || c.StationLimit > 1000 {
+		bad("station_limit", "must be between 1 and 1000, got %d", c.StationLimit)
+	}
+	if c.StationTTL < 0 {
+		bad("station_ttl", "must not be negative, got %v", c.StationTTL)
+	}
+	if c.DirectoryTTL < 0 {
+		bad("directory_ttl", "must not be negative, got %v", c.DirectoryTTL)
+	}
+	if c.DirectoryOrder != "popularity" && c.DirectoryOrder != "name" {
+		bad("directory_order", "must be popularity or name, got %q", c.DirectoryOrder)
+	}
+	if c.TagLimit < 1 || c.TagLimit > 10000 {
+		bad("tag_limit", "must be between 1 and 10000, got %d", c.TagLimit)
+	}
+	if c.FetchDebounce < 0 || c.FetchDebounce > time.Minute {
+		bad("fetch_debounce", "must be between 0 and 1m, got %v", c.FetchDebounce)
+	}
+	if c.MarqueeTick < 50*time.Millisecond {
+		bad("marquee_tick", "must be at least 50ms, got %v", c.MarqueeTick)
+	}
+	if c.ChunkVolumeDB < -60 || c.ChunkVolumeDB > 20 {
+		bad("chunk_volume_db", "must be between -60 and 20, got %v", c.ChunkVolumeDB)
+	}
+	if c.VisibleItems < 1 || c.VisibleItems > 15 || c.VisibleItems%2 == 0 {
+		bad("visible_items", "must be an odd number between 1 and 15, got %d", c.VisibleItems)
+	}
+	if !validColor(c.ActiveColor) {
+		bad("active_color", "must be an ANSI color 0-255 or #RRGGBB, got %q", c.ActiveColor)
+	}
+	if !validColor(c.InactiveColor) {
+		bad("inactive_color", "must be an ANSI color 0-255 or #RRGGBB, got %q", c.InactiveColor)
+	}
+	if len(c.Columns) == 0 || len(c.Columns) > 4 {
+		bad("columns", "must list 1 to 4 drums, got %d", len(c.Columns))
+	}
+	for _, m := range c.Mirrors {
+		if !strings.HasPrefix(m, "http://") && !strings.HasPrefix(m, "https://") {
+			bad("mirrors", "%q is not an http(s) URL", m)
+		}
+	}
+	if len(c.Providers) == 0 {
+		bad("providers", "must list at least one directory")
+	}
+	if c.IcecastTTL < 0 {
+		bad("icecast_ttl", "must not be negative, got %v", c.IcecastTTL)
+	}
+	if !strings.HasPrefix(c.IcecastURL, "http://") && !strings.HasPrefix(c.IcecastURL, "https://") {
+		bad("icecast_url", "%q is not an http(s) URL", c.IcecastURL)
+	}
+	return errors.Join(errs...)
+}
+
+// validColor reports whether s is a color lipgloss understands
+func validColor(s string) bool {
+	if !colorPattern.MatchString(s) {
+		return false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n <= 255
+	}
+	return true
+}
+
+// intSetter returns setter for an int field
+func intSetter(field func(c *Config) *int) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("not an integer: %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// durationSetter returns setter for a duration field
+func durationSetter(field func(c *Config) *time.Duration) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("not a duration: %q (use e.g. 300ms, 3s, 6h)", v)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// splitList splits comma-separated list, dropping empty entries
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package data
+
+import "strings"
+
+// Continents lists continents with their codes
+var Continents = []Category{
+	{Name: "Africa", Code: "AF"},
+	{Name: "Asia", Code: "AS"},
+	{Name: "Europe", Code: "EU"},
+	{Name: "North America", Code: "NA"},
+	{Name: "Oceania", Code: "OC"},
+	{Name: "South America", Code: "SA"},
+}
+
+// continentCountries lists ISO 3166-1 country codes per continent code
+var continentCountries = map[string]string{
+	"AF": "AO BF BI BJ BW CD CF CG CI CM CV DJ DZ EG EH ER ET GA GH GM GN GQ GW KE KM LR LS LY MA MG ML MR MU MW MZ NA NE NG RE RW SC SD SH SL SN SO SS ST SZ TD TG TN TZ UG YT ZA ZM ZW",
+	"AS": "AE AF AM AZ BD BH BN BT CN CY GE HK ID IL IN IQ IR JO JP KG KH KP KR KW KZ LA LB LK MM MN MO MV MY NP OM PH PK PS QA SA SG SY TH TJ TL TM TR TW UZ VN YE",
+	"EU": "AD AL AT AX BA BE BG BY CH CZ DE DK EE ES FI FO FR GB GG GI GR HR HU IE IM IS IT JE LI LT LU LV MC MD ME MK MT NL NO PL PT RO RS RU SE SI SJ SK SM UA VA XK",
+	"NA": "AG AI AW BB BL BM BQ BS BZ CA CR CU CW DM DO GD GL GP GT HN HT JM KN KY LC MF MQ MS MX NI PA PM PR SV SX TC TT US VC VG VI",
+	"OC": "AS AU CK FJ FM GU KI MH MP NC NF NR NU NZ PF PG PN PW SB TK TO TV UM VU WF WS",
+	"SA": "AR BO BR CL CO EC FK GF GY PE PY SR UY VE",
+}
+
+// continentByCountry maps country code to continent code
+var continentByCountry = func() map[string]string {
+	m := map[string]string{}
+	for continent, codes := range continentCountries {
+		for _, code := range strings.Fields(codes) {
+			m[code] = continent
+		}
+	}
+	return m
+}()
+
+// ContinentOf returns continent code of country code ("" if unknown)
+func ContinentOf(countryCode string) string {
+	return continentByCountry[strings.ToUpper(countryCode)]
+}
@@ -0,0 +1,31 @@
+package data
+
+// Category is a country or tag to browse stations by
+type Category struct {
+	Name     string `json:"name"`           // Display name (tag name for tags)
+	Code     string `json:"code,omitempty"` // ISO 3166-1 code (countries only)
+	Stations int    `json:"stations"`       // Number of working stations (0: unknown)
+}
+
+// Directory is the list of countries, tags and languages to browse
+type Directory struct {
+	Countries []Category `json:"countries"`
+	Tags      []Category `json:"tags"`
+	Languages []Category `json:"languages"`
+}
+
+// StaticDirectory returns embedded lists without station counts
+// Used until the live lists are loaded and when the API is unreachable
+func StaticDirectory() Directory {
+	var dir Directory
+	for _, c := range Countries {
+		dir.Countries = append(dir.Countries, Category{Name: c.Name, Code: c.Code})
+	}
+	for _, g := range Genre {
+		dir.Tags = append(dir.Tags, Category{Name: g})
+	}
+	for _, l := range Languages {
+		dir.Languages = append(dir.Languages, Category{Name: l})
+	}
+	return dir
+}
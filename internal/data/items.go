@@ -158,3 +158,29 @@ var Genre = []string{
 	"Post-punk",
 	"Drum",
 }
+
+// Languages is the list of station languages (Radio Browser spelling)
+var Languages = []string{
+	"english",
+	"spanish",
+	"german",
+	"french",
+	"italian",
+	"portuguese",
+	"russian",
+	"greek",
+	"polish",
+	"dutch",
+	"turkish",
+	"arabic",
+	"chinese",
+	"japanese",
+	"korean",
+	"hindi",
+	"indonesian",
+	"romanian",
+	"hungarian",
+	"czech",
+	"swedish",
+	"ukrainian",
+}
@@ -1,7 +1,61 @@
 package data
 
+import (
+	"strconv"
+	"time"
+)
+
 // Station represents a radio station
 type Station struct {
 	Name string `json:"name"`
 	Link string `json:"url"`
+
+	// Directory details (empty when the source does not provide them)
+	Source      string    `json:"source,omitempty"`      // Provider that listed the station
+	UUID        string    `json:"uuid,omitempty"`        // Radio Browser station UUID
+	Homepage    string    `json:"homepage,omitempty"`    // Station website
+	Favicon     string    `json:"favicon,omitempty"`     // Logo URL
+	Tags        []string  `json:"tags,omitempty"`        // Genres and other tags
+	Language    string    `json:"language,omitempty"`    // Broadcast language(s)
+	Codec       string    `json:"codec,omitempty"`       // Stream codec (MP3, AAC, ...)
+	Bitrate     int       `json:"bitrate,omitempty"`     // Stream bitrate in kbps
+	Country     string    `json:"country,omitempty"`     // Country name
+	CountryCode string    `json:"countrycode,omitempty"` // ISO 3166-1 alpha-2 code
+	State       string    `json:"state,omitempty"`       // State or region
+	GeoLat      float64   `json:"geo_lat,omitempty"`     // Latitude (0 if unknown)
+	GeoLong     float64   `json:"geo_long,omitempty"`    // Longitude (0 if unknown)
+	Votes       int       `json:"votes,omitempty"`       // User votes
+	Clicks      int       `json:"clicks,omitempty"`      // Click count
+	LastCheckOK bool      `json:"lastcheckok,omitempty"` // Stream was reachable at last check
+	LastCheck   time.Time `json:"lastcheck,omitzero"`    // Time of last directory check
+	Song        string    `json:"song,omitempty"`        // Song playing when the directory was read
+}
+
+// HasGeo reports whether station has coordinates
+func (s Station) HasGeo() bool {
+	return s.GeoLat != 0 || s.GeoLong != 0
+}
+
+// Quality returns codec and bitrate, e.g. "MP3 128 kbps"
+func (s Station) Quality() string {
+	switch {
+	case s.Codec != "" && s.Bitrate > 0:
+		return s.Codec + " " + strconv.Itoa(s.Bitrate) + " kbps"
+	case s.Bitrate > 0:
+		return strconv.Itoa(s.Bitrate) + " kbps"
+	default:
+		return s.Codec
+	}
+}
+
+// Location returns state and country, e.g. "Bavaria, Germany"
+func (s Station) Location() string {
+	switch {
+	case s.State != "" && s.Country != "":
+		return s.State + ", " + s.Country
+	case s.Country != "":
+		return s.Country
+	default:
+		return s.State
+	}
 }
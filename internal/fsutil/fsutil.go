@@ -0,0 +1,25 @@
+// Package fsutil holds file helpers shared by the cache and user data stores
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data atomically (readers never see a partial file)
+func WriteFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,18 +1,24 @@
 package logger
 
 import (
+	"io"
 	"log"
 	"os"
 )
 
-var Log *log.Logger
+// Log is the application logger (discards output until Open is called)
+var Log = log.New(io.Discard, "", 0)
 
-func init() {
-	f, err := os.OpenFile("/tmp/crr.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+// Open directs log to file at path (appending); empty path disables logging
+func Open(path string) error {
+	if path == "" {
+		Log = log.New(io.Discard, "", 0)
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
-		// If file cannot be opened - skip logging
-		Log = log.New(os.Stderr, "", 0)
-		return
+		return err
 	}
 	Log = log.New(f, "", log.LstdFlags)
+	return nil
 }
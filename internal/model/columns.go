@@ -0,0 +1,217 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crr/internal/data"
+	"crr/internal/provider"
+)
+
+// Column kinds (names used in config and command palette)
+const (
+	ColumnContinent = "continent"
+	ColumnCountry   = "country"
+	ColumnState     = "state"
+	ColumnLanguage  = "language"
+	ColumnTag       = "tag"
+)
+
+// DefaultColumns is the drum layout used when none is configured
+var DefaultColumns = []string{ColumnCountry, ColumnTag}
+
+// columnAliases are other names of column kinds
+var columnAliases = map[string]string{
+	"genre":  ColumnTag,
+	"region": ColumnState,
+}
+
+// AllStates is the State drum entry that does not narrow stations
+const AllStates = "All"
+
+// Scope is the selection of the drums left of a column
+type Scope struct {
+	provider.StationQuery
+	Continent string // Continent code (narrows countries only)
+	Country   string // Country name (states are listed by name)
+}
+
+// Column is a category drum left of the Station drum
+// Items with an empty Value (Favorites, All) do not narrow the scope
+type Column interface {
+	Kind() string                   // Kind name ("country")
+	Title() string                  // Drum title
+	Items(d *Drums, s Scope) []Item // Items available within scope
+	Narrow(s *Scope, it Item)       // Narrows scope to item
+}
+
+// columnLoader is a column whose items are fetched on demand
+type columnLoader interface {
+	Load(d *Drums, s Scope) tea.Cmd // Returns nil if items are known already
+}
+
+// newColumn returns column of kind
+func newColumn(kind string) (Column, bool) {
+	switch kind {
+	case ColumnContinent:
+		return continentColumn{}, true
+	case ColumnCountry:
+		return countryColumn{}, true
+	case ColumnState:
+		return stateColumn{}, true
+	case ColumnLanguage:
+		return languageColumn{}, true
+	case ColumnTag:
+		return tagColumn{}, true
+	}
+	return nil, false
+}
+
+// NewColumns returns category drums for kinds, left to right (nil: DefaultColumns)
+// Each kind may be used once; a state drum needs a country drum before it,
+// a continent drum needs one after it
+func NewColumns(kinds []string) ([]Column, error) {
+	if len(kinds) == 0 {
+		kinds = DefaultColumns
+	}
+
+	var errs []string
+	var cols []Column
+	at := map[string]int{}
+	for _, name := range kinds {
+		kind := strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := columnAliases[kind]; ok {
+			kind = alias
+		}
+		col, ok := newColumn(kind)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("columns: unknown column %q", name))
+			continue
+		}
+		if _, ok := at[kind]; ok {
+			errs = append(errs, fmt.Sprintf("columns: %s is used twice", kind))
+			continue
+		}
+		at[kind] = len(cols)
+		cols = append(cols, col)
+	}
+
+	country, hasCountry := at[ColumnCountry]
+	if i, ok := at[ColumnState]; ok && (!hasCountry || country > i) {
+		errs = append(errs, "columns: state needs a country column before it")
+	}
+	if i, ok := at[ColumnContinent]; ok && (!hasCountry || country < i) {
+		errs = append(errs, "columns: continent needs a country column after it")
+	}
+
+	if len(errs) > 0 {
+		slices.Sort(errs)
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return cols, nil
+}
+
+// categoryItems returns drum items for categories in directory order
+func (d *Drums) categoryItems(cats []data.Category) []Item {
+	sorted := sortCategories(cats, d.OrderByName)
+	items := make([]Item, len(sorted))
+	for i, c := range sorted {
+		items[i] = Item{Name: c.Name, Value: c}
+	}
+	return items
+}
+
+// continentColumn lists continents with countries in the directory
+type continentColumn struct{}
+
+func (continentColumn) Kind() string  { return ColumnContinent }
+func (continentColumn) Title() string { return "Continent" }
+
+// Items returns continents with the stations of their countries
+func (continentColumn) Items(d *Drums, s Scope) []Item {
+	var cats []data.Category
+	for _, c := range data.Continents {
+		found := false
+		for _, country := range d.Directory.Countries {
+			if data.ContinentOf(country.Code) == c.Code {
+				c.Stations += country.Stations
+				found = true
+			}
+		}
+		if found {
+			cats = append(cats, c)
+		}
+	}
+	return d.categoryItems(cats)
+}
+
+func (continentColumn) Narrow(s *Scope, it Item) { s.Continent = it.Value.Code }
+
+// countryColumn lists countries (of selected continent)
+type countryColumn struct{}
+
+func (countryColumn) Kind() string  { return ColumnCountry }
+func (countryColumn) Title() string { return "Countries" }
+
+// Items returns countries of the directory within continent of scope
+func (countryColumn) Items(d *Drums, s Scope) []Item {
+	cats := d.Directory.Countries
+	if s.Continent != "" {
+		cats = nil
+		for _, c := range d.Directory.Countries {
+			if data.ContinentOf(c.Code) == s.Continent {
+				cats = append(cats, c)
+			}
+		}
+	}
+	return d.categoryItems(cats)
+}
+
+func (countryColumn) Narrow(s *Scope, it Item) {
+	s.CountryCode = it.Value.Code
+	s.Country = it.Value.Name
+}
+
+// stateColumn lists states and regions of selected country (loaded on demand)
+type stateColumn struct{}
+
+func (stateColumn) Kind() string  { return ColumnState }
+func (stateColumn) Title() string { return "Region" }
+
+// Items returns All followed by known states of country
+func (stateColumn) Items(d *Drums, s Scope) []Item {
+	return append(textItems(AllStates), d.categoryItems(d.States[s.CountryCode])...)
+}
+
+func (stateColumn) Narrow(s *Scope, it Item) { s.State = it.Value.Name }
+
+// Load fetches states of country once (an entry marks the request in flight)
+func (stateColumn) Load(d *Drums, s Scope) tea.Cmd {
+	if s.CountryCode == "" {
+		return nil
+	}
+	if _, ok := d.States[s.CountryCode]; ok {
+		return nil
+	}
+	d.States[s.CountryCode] = nil
+	return DoLoadStates(s.CountryCode, s.Country)
+}
+
+// languageColumn lists station languages
+type languageColumn struct{}
+
+func (languageColumn) Kind() string                   { return ColumnLanguage }
+func (languageColumn) Title() string                  { return "Language" }
+func (languageColumn) Items(d *Drums, s Scope) []Item { return d.categoryItems(d.Directory.Languages) }
+func (languageColumn) Narrow(s *Scope, it Item)       { s.Language = it.Value.Name }
+
+// tagColumn lists tags (genres)
+type tagColumn struct{}
+
+func (tagColumn) Kind() string                   { return ColumnTag }
+func (tagColumn) Title() string                  { return "Genre" }
+func (tagColumn) Items(d *Drums, s Scope) []Item { return d.categoryItems(d.Directory.Tags) }
+func (tagColumn) Narrow(s *Scope, it Item)       { s.Tag = it.Value.Name }
@@ -0,0 +1,164 @@
+package model
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crr/internal/data"
+	"crr/internal/logger"
+	"crr/internal/provider"
+)
+
+// DirectoryOrder is the initial order of category drums (set from config)
+// "popularity" (most stations first) or "name"
+var DirectoryOrder = "popularity"
+
+// applyDirectory replaces countries, tags and languages, keeping selected entries
+// Stations are reloaded only if a selected entry is gone
+func (d *Drums) applyDirectory(dir data.Directory) tea.Cmd {
+	old := d.selection()
+	d.setDirectory(dir)
+	return d.checkFetchDebounce(old)
+}
+
+// setDirectory fills category drums, keeping selected entries
+func (d *Drums) setDirectory(dir data.Directory) {
+	if len(dir.Languages) == 0 {
+		dir.Languages = data.StaticDirectory().Languages // Cached by an older version
+	}
+	d.Directory = dir
+	d.fillColumns(0)
+}
+
+// fillColumns refills category drums from column from on for the selection
+// left of them, keeping selected entries
+// Entries found empty before are hidden, Favorites lead the first drum
+func (d *Drums) fillColumns(from int) {
+	for i := from; i < len(d.Columns); i++ {
+		col := d.Columns[i]
+		scope := d.scope(i)
+		var items []Item
+		if i == 0 {
+			items = append(items, Item{Name: FavoritesSource})
+		}
+		for _, it := range col.Items(d, scope) {
+			s := scope
+			col.Narrow(&s, it)
+			if it.Value.Name != "" && d.Empty[s.Key()] {
+				continue // Nothing found here last time
+			}
+			items = append(items, it)
+		}
+		d.List[i].SetItems(items)
+		d.List[i].Title = d.directoryTitle(col.Title())
+
+		// Restored selection may only be listed now (live lists, states)
+		if name, ok := d.pending[col.Kind()]; ok && d.List[i].Select(name) {
+			delete(d.pending, col.Kind())
+		}
+	}
+}
+
+// loadColumns fetches entries of drums that are loaded on demand
+func (d *Drums) loadColumns() tea.Cmd {
+	var cmds []tea.Cmd
+	for i, col := range d.Columns {
+		if l, ok := col.(columnLoader); ok {
+			cmds = append(cmds, l.Load(d, d.scope(i)))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyStates fills State drum with loaded states of a country
+func (d *Drums) applyStates(msg StatesMsg) tea.Cmd {
+	if msg.Err != nil {
+		logger.Log.Printf("Load states of %s: %v", msg.Country, msg.Err)
+		delete(d.States, msg.Country) // Retried when the country is selected again
+		return nil
+	}
+	d.States[msg.Country] = msg.States
+	old := d.selection()
+	d.fillColumns(0)
+	return d.checkFetchDebounce(old)
+}
+
+// scope returns selection of the first n category drums
+func (d *Drums) scope(n int) Scope {
+	var s Scope
+	for i := 0; i < n && i < len(d.Columns); i++ {
+		if it := d.List[i].Selected(); it.Value.Name != "" {
+			d.Columns[i].Narrow(&s, it)
+		}
+	}
+	return s
+}
+
+// query returns station query of the selected entries
+func (d *Drums) query() provider.StationQuery {
+	return d.scope(len(d.Columns)).StationQuery
+}
+
+// selection returns keys of selected entries (Favorites only, as the
+// other drums do not apply to them)
+func (d *Drums) selection() []string {
+	if d.IsFavoritesSource() {
+		return []string{FavoritesSource}
+	}
+	keys := make([]string, len(d.Columns))
+	for i := range d.Columns {
+		keys[i] = d.List[i].Selected().key()
+	}
+	return keys
+}
+
+// columnIndex returns index of drum of column kind or -1
+func (d *Drums) columnIndex(kind string) int {
+	for i, col := range d.Columns {
+		if col.Kind() == kind {
+			return i
+		}
+	}
+	return -1
+}
+
+// markEmpty remembers that the selection has no stations
+// Its entry is hidden the next time the drums left of it are selected
+func (d *Drums) markEmpty() {
+	if q := d.query(); !q.IsEmpty() {
+		d.Empty[q.Key()] = true
+	}
+}
+
+// toggleOrder switches category drums between popularity and name order
+func (d *Drums) toggleOrder() tea.Cmd {
+	d.OrderByName = !d.OrderByName
+	d.setDirectory(d.Directory)
+	return nil
+}
+
+// directoryTitle returns drum title with sort order
+func (d *Drums) directoryTitle(title string) string {
+	if d.OrderByName {
+		return title + " (A-Z)"
+	}
+	return title
+}
+
+// sortCategories returns categories by name or by station count
+// Equal counts (and static lists without counts) keep their order
+func sortCategories(cats []data.Category, byName bool) []data.Category {
+	out := slices.Clone(cats)
+	if byName {
+		slices.SortStableFunc(out, func(a, b data.Category) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		})
+	} else {
+		slices.SortStableFunc(out, func(a, b data.Category) int {
+			return b.Stations - a.Stations
+		})
+	}
+	return out
+}
@@ -1,11 +1,41 @@
 // Package model contains data models for the TUI application
 package model
 
+import (
+	"strconv"
+	"strings"
+
+	"crr/internal/data"
+)
+
+// Item is a drum entry: the text shown and the category it stands for
+type Item struct {
+	Name  string        // Text shown in the drum
+	Value data.Category // Category (code, station count); zero for stations and placeholders
+}
+
+// textItems returns items showing texts only
+func textItems(texts ...string) []Item {
+	items := make([]Item, len(texts))
+	for i, t := range texts {
+		items[i] = Item{Name: t}
+	}
+	return items
+}
+
+// key returns identity of item kept across refills (code, else name in lower case)
+func (it Item) key() string {
+	if it.Value.Code != "" {
+		return it.Value.Code
+	}
+	return strings.ToLower(it.Name)
+}
+
 // Drum represents a single column (drum) with selectable items
 type Drum struct {
-	Items  []string // List of items in the column (countries, genres, or stations)
-	Active int      // Index of the currently selected item
-	Title  string   // Column title
+	Items  []Item // List of items in the column (categories or stations)
+	Active int    // Index of the currently selected item
+	Title  string // Column title
 }
 
 // Len returns the number of items in the drum
@@ -29,9 +59,81 @@ func (d *Drum) MoveDown() {
 	}
 }
 
-// GetItem returns item at index with wrap-around support
-func (d *Drum) GetItem(index int) string {
+// MoveTo selects item at index with wrap-around (so Active+n moves by n)
+func (d *Drum) MoveTo(index int) {
+	n := d.Len()
+	if n == 0 {
+		return
+	}
+	d.Active = ((index % n) + n) % n
+}
+
+// item returns item at index with wrap-around support (zero if empty)
+func (d *Drum) item(index int) Item {
 	n := d.Len()
+	if n == 0 {
+		return Item{}
+	}
 	idx := ((index % n) + n) % n // Normalize index for wrap-around
 	return d.Items[idx]
 }
+
+// GetItem returns name of item at index with wrap-around support
+func (d *Drum) GetItem(index int) string {
+	return d.item(index).Name
+}
+
+// Selected returns the active item (zero if the drum is empty)
+func (d *Drum) Selected() Item {
+	return d.item(d.Active)
+}
+
+// Names returns names of all items
+func (d *Drum) Names() []string {
+	names := make([]string, len(d.Items))
+	for i, it := range d.Items {
+		names[i] = it.Name
+	}
+	return names
+}
+
+// Label returns item at index with its station count (wraps like GetItem)
+func (d *Drum) Label(index int) string {
+	it := d.item(index)
+	if it.Value.Stations > 0 {
+		return it.Name + " (" + strconv.Itoa(it.Value.Stations) + ")"
+	}
+	return it.Name
+}
+
+// SetItems replaces items keeping the selected one if it is still there
+// (otherwise the selection stays at the same position)
+func (d *Drum) SetItems(items []Item) {
+	old := d.Selected().key()
+	d.Items = items
+	for i, it := range items {
+		if it.key() == old {
+			d.Active = i
+			return
+		}
+	}
+	d.Active = max(0, min(d.Active, len(items)-1))
+}
+
+// Select makes item active (selection is unchanged if item is missing)
+// An exact match wins over one differing in case only
+func (d *Drum) Select(item string) bool {
+	for i, it := range d.Items {
+		if it.Name == item {
+			d.Active = i
+			return true
+		}
+	}
+	for i, it := range d.Items {
+		if strings.EqualFold(it.Name, item) {
+			d.Active = i
+			return true
+		}
+	}
+	return false
+}
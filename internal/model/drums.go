@@ -1,41 +1,91 @@
 package model
 
 import (
+	"context"
+
 	tea "github.com/charmbracelet/bubbletea"
 
 	"crr/internal/data"
+	"crr/internal/logger"
 	"crr/internal/player"
+	"crr/internal/provider"
+	"crr/internal/store"
+	"crr/internal/ui"
 )
 
-// Drums is the main application model containing three columns
+// FavoritesSource is the first drum entry listing starred stations
+const FavoritesSource = "Favorites"
+
+// Drums is the main application model: category drums followed by Station drum
 type Drums struct {
-	List         [3]Drum // Array of three columns (Countries, Genre, Station)
-	Active       int     // Index of currently active column (0, 1, or 2)
-	ScrollOffset int     // Offset for marquee text animation
-	Width        int     // Terminal width in characters
-	Height       int     // Terminal height in characters
+	List         []Drum   // Drums left to right, one per column and Station last
+	Columns      []Column // Kinds of category drums (List without Station)
+	Active       int      // Index of currently active drum
+	ScrollOffset int      // Offset for marquee text animation
+	Width        int      // Terminal width in characters
+	Height       int      // Terminal height in characters
 
 	// Header panel components
 	Track  *Track  // Track info (left side)
 	Volume *Volume // Volume control (center)
 	Clock  *Clock  // Clock display (right side)
 
+	// Categories
+	Directory   data.Directory             // Countries, tags and languages
+	States      map[string][]data.Category // States per country code (nil while loading)
+	OrderByName bool                       // Lists are sorted by name, not station count
+	Empty       map[string]bool            // Station queries found empty (by key)
+	pending     map[string]string          // Restored entries not listed yet (per column kind)
+
 	// Station loading
 	Stations   []data.Station // Loaded stations
-	DebounceID int            // Current debounce timer ID
+	DebounceID int            // Current debounce timer ID (also tags station requests)
 	Loading    bool           // Station loading flag
+	FromCache  bool           // Shown stations come from cache (refresh pending)
+	Offline    bool           // Last refresh failed, cached stations are shown
+
+	Filter       provider.Filter // Sort order and quality filters of station requests
+	MoreStations bool            // Next page of stations may exist
+	Pages        int             // Pages of stations loaded
+	LoadingMore  bool            // Next page is being loaded
+
+	cancelFetch  context.CancelFunc // Cancels station request in flight
+	cancelSearch context.CancelFunc // Cancels search request in flight
+
+	// Details panel
+	ShowDetails bool // Show directory details of selected station
+
+	// Favorites
+	Favorites []store.Favorite // Starred stations (saved in config dir)
+
+	// Presets
+	Presets   store.Presets // Memory slots on keys 1-9 (saved in config dir)
+	StoreMode bool          // Next digit stores current station
+
+	// Keys
+	Keys        *KeyMap  // Key bindings
+	PendingKeys []string // Keys of an unfinished chord
+	Count       Count    // Count prefix of the next motion
+	Jump        Jump     // Type-to-jump in active drum
+
+	// Overlays
+	ShowHelp bool    // Help overlay is shown
+	Palette  Palette // ":" command line
+	Search   Search  // "/" station search
 
 	// Playback
-	Player           *player.Player // Audio player
-	CurrentStreamURL string         // Current stream URL (for metadata)
+	Player            *player.Player // Audio player
+	CurrentStreamURL  string         // Current stream URL (for metadata)
+	CurrentStreamName string         // Current station name (for status)
+	KeepStream        bool           // Next station list must not interrupt current stream
 }
 
-// ColumnWidth returns the width of a single column (one third of terminal)
+// ColumnWidth returns the width of a single column (terminal split evenly)
 func (d *Drums) ColumnWidth() int {
-	if d.Width < 30 {
+	if d.Width < 10*len(d.List) {
 		return 20 // Minimum width
 	}
-	return d.Width / 3
+	return d.Width / len(d.List)
 }
 
 // InnerWidth returns inner column width (without column border)
@@ -54,57 +104,175 @@ func (d *Drums) MaxTextWidth() int {
 	return d.ItemWidth() - 4 // Minus inner borders and padding
 }
 
-// NewDrums creates a new Drums instance
-func NewDrums() *Drums {
-	countries := Drum{data.CountryNames(), 0, "Countries"}
-	genre := Drum{data.Genre, 0, "Genre"}
-	station := Drum{[]string{"Loading..."}, 0, "Station"}
+// NewDrums creates a new Drums instance playing through p
+// controlled with keys (nil for default bindings)
+// with category drums of columns (nil for DefaultColumns)
+func NewDrums(p *player.Player, keys *KeyMap, columns []Column) *Drums {
+	if keys == nil {
+		keys = DefaultKeyMap()
+	}
+	if columns == nil {
+		columns, _ = NewColumns(nil)
+	}
+
+	// Embedded lists until live ones are loaded (see applyDirectory)
+	list := make([]Drum, len(columns)+1)
+	list[len(columns)] = Drum{Items: textItems("Loading..."), Title: "Station"}
+
+	favorites, err := store.LoadFavorites()
+	if err != nil {
+		logger.Log.Printf("Load favorites: %v", err)
+	}
 
-	// Extract embedded chunks to temp directory
-	chunksDir, err := player.ExtractChunks()
+	presets, err := store.LoadPresets()
 	if err != nil {
-		chunksDir = "chunks" // Fallback to local directory
+		logger.Log.Printf("Load presets: %v", err)
+	}
+
+	d := &Drums{
+		List:        list,
+		Columns:     columns,
+		Active:      0,
+		States:      map[string][]data.Category{},
+		OrderByName: DirectoryOrder == "name",
+		Empty:       map[string]bool{},
+		pending:     map[string]string{},
+		Track:       NewTrack(),
+		Volume:      NewVolume(),
+		Clock:       NewClock(),
+		Loading:     true,
+		Player:      p,
+		Favorites:   favorites,
+		Presets:     presets,
+		Keys:        keys,
+	}
+	d.setDirectory(data.StaticDirectory())
+	d.List[0].Active = 1 // Favorites come first, one step above the first entry
+	d.fillColumns(1)
+
+	session, ok, err := store.LoadSession()
+	if err != nil {
+		logger.Log.Printf("Load session: %v", err)
+	}
+	if ok {
+		d.restoreSession(session)
 	}
+	return d
+}
+
+// restoreSession puts drums, volume and stream back as they were on exit
+func (d *Drums) restoreSession(s store.Session) {
+	selections := s.Selections
+	if selections == nil {
+		// Saved before drums were configurable
+		selections = map[string]string{ColumnCountry: s.Country, ColumnTag: s.Genre}
+	}
+	for i, col := range d.Columns {
+		name := selections[col.Kind()]
+		if name != "" && !d.List[i].Select(name) {
+			d.pending[col.Kind()] = name
+		}
+		d.fillColumns(i + 1)
+	}
+	if s.Active >= 0 && s.Active < len(d.List) {
+		d.Active = s.Active
+	}
+	d.Volume.SetLevel(s.Volume)
+	d.Volume.Muted = s.Muted
 
-	return &Drums{
-		List:    [3]Drum{countries, genre, station},
-		Active:  0,
-		Track:   NewTrack(),
-		Volume:  NewVolume(),
-		Clock:   NewClock(),
-		Loading: true,
-		Player:  player.New(chunksDir),
+	if s.StationURL != "" {
+		// Played right away by Init, the station list reloads in background
+		d.tuneTo(data.Station{Name: s.StationName, Link: s.StationURL})
+		d.KeepStream = true
+	}
+}
+
+// Session returns state to restore on the next launch
+func (d *Drums) Session() store.Session {
+	selections := map[string]string{}
+	for i, col := range d.Columns {
+		selections[col.Kind()] = d.List[i].GetItem(d.List[i].Active)
+	}
+	return store.Session{
+		Active:      d.Active,
+		Selections:  selections,
+		StationURL:  d.CurrentStreamURL,
+		StationName: d.CurrentStreamName,
+		Volume:      d.Volume.Level,
+		Muted:       d.Volume.Muted,
 	}
 }
 
 // Init initializes the model (required by tea.Model interface)
 func (d Drums) Init() tea.Cmd {
-	countryCode := d.CurrentCountryCode()
-	genre := d.CurrentGenre()
-	// Play chunk immediately on startup
-	d.Player.PlayChunkImmediately()
+	load := DoLoadCachedStations(d.DebounceID, d.query(), d.Filter) // Initial station load (cache first)
+	if d.IsFavoritesSource() {
+		load = DoShowFavorites(d.DebounceID)
+	}
+	// Apply initial volume, then play chunk immediately on startup
+	// (or resume last station with its crossfade)
+	d.applyVolume()
+	play := DoPlayStream(d.Player, d.CurrentStreamURL)
+	if d.CurrentStreamURL == "" {
+		d.Player.PlayChunkImmediately()
+		play = nil
+	}
 	return tea.Batch(
+		play,
 		DoTick(),
 		DoClockTick(),
-		DoMetadataTick(),                    // Metadata update timer
-		DoFetchStations(countryCode, genre), // Initial station load
+		DoWaitMetadata(d.Player.Metadata()), // Track changes pushed by player
+		DoWaitState(d.Player.Events()),      // Connection state of current stream
+		DoLoadDirectory(),                   // Live countries, tags and languages
+		d.loadColumns(),                     // States of selected country
+		load,
 	)
 }
 
-// CurrentCountry returns the name of currently selected country (for UI)
-func (d *Drums) CurrentCountry() string {
-	return d.List[0].GetItem(d.List[0].Active)
+// IsFavoritesSource reports whether first drum is on Favorites
+func (d *Drums) IsFavoritesSource() bool {
+	it := d.List[0].Selected()
+	return it.Name == FavoritesSource && it.Value.Name == ""
 }
 
 // CurrentCountryCode returns the code of currently selected country (for API)
 func (d *Drums) CurrentCountryCode() string {
-	name := d.CurrentCountry()
-	return data.CountryCodeByName(name)
+	return d.query().CountryCode
 }
 
 // CurrentGenre returns the currently selected genre
 func (d *Drums) CurrentGenre() string {
-	return d.List[1].GetItem(d.List[1].Active)
+	return d.query().Tag
+}
+
+// StationDrum returns a pointer to the Station drum (the last one)
+func (d *Drums) StationDrum() *Drum {
+	return &d.List[len(d.List)-1]
+}
+
+// isStationColumn reports whether Station drum is active
+func (d *Drums) isStationColumn() bool {
+	return d.Active == len(d.List)-1
+}
+
+// CurrentStation returns currently selected station
+func (d *Drums) CurrentStation() (data.Station, bool) {
+	idx := d.StationDrum().Active
+	if idx < 0 || idx >= len(d.Stations) {
+		return data.Station{}, false
+	}
+	return d.Stations[idx], true
+}
+
+// CurrentStationName returns name of currently selected station
+func (d *Drums) CurrentStationName() string {
+	st, _ := d.CurrentStation()
+	return st.Name
+}
+
+// pageSize returns number of items scrolled by page keys
+func (d *Drums) pageSize() int {
+	return ui.VisibleItems
 }
 
 // ActiveDrum returns a pointer to the currently active column
@@ -121,7 +289,7 @@ func (d *Drums) MoveLeft() {
 
 // MoveRight switches to the column on the right
 func (d *Drums) MoveRight() {
-	if d.Active < 2 {
+	if d.Active < len(d.List)-1 {
 		d.Active++
 	}
 }
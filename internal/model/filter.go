@@ -0,0 +1,154 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crr/internal/provider"
+)
+
+// filterCodecs are codecs offered by the codec cycle ("" means any)
+var filterCodecs = []string{"", "MP3", "AAC", "AAC+", "OGG", "FLAC"}
+
+// filterBitrates are minimum bitrates offered by the bitrate cycle (kbps)
+var filterBitrates = []int{0, 64, 128, 192, 256, 320}
+
+// cycleSort switches to the next sort order
+func (d *Drums) cycleSort() tea.Cmd {
+	i := slices.Index(provider.SortOrders, d.Filter.Sort) // -1 for the empty default
+	d.Filter.Sort = provider.SortOrders[(max(i, 0)+1)%len(provider.SortOrders)]
+	return d.applyFilter()
+}
+
+// cycleCodec switches to the next codec filter
+func (d *Drums) cycleCodec() tea.Cmd {
+	i := slices.IndexFunc(filterCodecs, func(c string) bool { return strings.EqualFold(c, d.Filter.Codec) })
+	d.Filter.Codec = filterCodecs[(i+1)%len(filterCodecs)]
+	return d.applyFilter()
+}
+
+// cycleBitrate switches to the next minimum bitrate
+func (d *Drums) cycleBitrate() tea.Cmd {
+	next := filterBitrates[0]
+	for _, b := range filterBitrates {
+		if b > d.Filter.MinBitrate {
+			next = b
+			break
+		}
+	}
+	d.Filter.MinBitrate = next
+	return d.applyFilter()
+}
+
+// applyFilter reloads shown stations with current sort and filters
+// The playing stream keeps playing; favorites are not filtered
+func (d *Drums) applyFilter() tea.Cmd {
+	if d.Search.Shown {
+		d.Search.Sent = "" // Ask again even if query is unchanged
+		return d.startSearch()
+	}
+	if d.IsFavoritesSource() {
+		d.updateStationTitle()
+		return nil
+	}
+
+	d.DebounceID++
+	d.stopFetch()
+	d.KeepStream = d.CurrentStreamURL != ""
+	d.clearStations("Scanning...")
+	id := d.DebounceID
+	return func() tea.Msg {
+		return FetchDebounceMsg{ID: id} // No need to wait, selection did not move
+	}
+}
+
+// runSort sets sort order from the command palette
+func runSort(d *Drums, arg string) (tea.Cmd, error) {
+	sort, err := provider.ParseSort(arg)
+	if err != nil {
+		return nil, err
+	}
+	d.Filter.Sort = sort
+	return d.applyFilter(), nil
+}
+
+// runCodec sets codec filter from the command palette ("any" clears it)
+func runCodec(d *Drums, arg string) (tea.Cmd, error) {
+	d.Filter.Codec = strings.ToUpper(arg)
+	if isAny(arg) {
+		d.Filter.Codec = ""
+	}
+	return d.applyFilter(), nil
+}
+
+// runBitrate sets minimum bitrate from the command palette
+func runBitrate(d *Drums, arg string) (tea.Cmd, error) {
+	if isAny(arg) {
+		arg = "0"
+	}
+	kbps, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(arg), "k"))
+	if err != nil || kbps < 0 {
+		return nil, fmt.Errorf("bitrate must be kbps like 128, got %q", arg)
+	}
+	d.Filter.MinBitrate = kbps
+	return d.applyFilter(), nil
+}
+
+// runHTTPS sets HTTPS-only filter from the command palette
+func runHTTPS(d *Drums, arg string) (tea.Cmd, error) {
+	switch strings.ToLower(arg) {
+	case "on", "yes", "true":
+		d.Filter.HTTPSOnly = true
+	case "off", "no", "false":
+		d.Filter.HTTPSOnly = false
+	default:
+		return nil, fmt.Errorf("https must be on or off, got %q", arg)
+	}
+	return d.applyFilter(), nil
+}
+
+// runLanguage sets language filter from the command palette ("any" clears it)
+func runLanguage(d *Drums, arg string) (tea.Cmd, error) {
+	d.Filter.Language = strings.ToLower(arg)
+	if isAny(arg) {
+		d.Filter.Language = ""
+	}
+	return d.applyFilter(), nil
+}
+
+// isAny reports whether palette argument clears a filter
+func isAny(arg string) bool {
+	switch strings.ToLower(arg) {
+	case "any", "all", "off", "none":
+		return true
+	}
+	return false
+}
+
+// sortCandidates returns sort orders for completion
+func sortCandidates(d *Drums) []string {
+	return slices.Clone(provider.SortOrders)
+}
+
+// codecCandidates returns codecs for completion
+func codecCandidates(d *Drums) []string {
+	return append([]string{"any"}, filterCodecs[1:]...)
+}
+
+// bitrateCandidates returns bitrate steps for completion
+func bitrateCandidates(d *Drums) []string {
+	out := []string{"any"}
+	for _, b := range filterBitrates[1:] {
+		out = append(out, strconv.Itoa(b))
+	}
+	return out
+}
+
+// switchCandidates returns on and off for completion
+func switchCandidates(d *Drums) []string {
+	return []string{"on", "off"}
+}
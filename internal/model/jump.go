@@ -0,0 +1,187 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Jump is type-to-jump state of the active drum
+type Jump struct {
+	Query string // Typed text (empty: not jumping)
+	ID    int    // Current timeout ID
+}
+
+// Count is a count prefix typed before a motion key ("10j")
+type Count struct {
+	Keys []string // Typed digits
+	ID   int      // Current timeout ID
+}
+
+// motionActions are actions repeated by a count prefix
+var motionActions = map[Action]bool{
+	ActionMoveUp:   true,
+	ActionMoveDown: true,
+	ActionPageUp:   true,
+	ActionPageDown: true,
+}
+
+// jumpKey extends type-to-jump in progress
+// Reports false if key ends jumping and must be handled as usual
+func (d *Drums) jumpKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if d.Jump.Query == "" {
+		return nil, false
+	}
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		if msg.Alt {
+			break
+		}
+		return d.jump(d.Jump.Query + string(msg.Runes)), true
+	case tea.KeyBackspace:
+		runes := []rune(d.Jump.Query)
+		if len(runes) > 1 {
+			return d.jump(string(runes[:len(runes)-1])), true
+		}
+		d.Jump.Query = ""
+		return nil, true
+	case tea.KeyEsc, tea.KeyEnter:
+		d.Jump.Query = ""
+		return nil, true
+	}
+	d.Jump.Query = ""
+	return nil, false
+}
+
+// startJump starts type-to-jump with a printable key bound to nothing
+func (d *Drums) startJump(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if msg.Type != tea.KeyRunes || msg.Alt || len(msg.Runes) == 0 || !unicode.IsPrint(msg.Runes[0]) {
+		return nil, false
+	}
+	return d.jump(string(msg.Runes)), true
+}
+
+// jump selects first item of active drum matching query
+// The selection is kept while it still matches
+func (d *Drums) jump(query string) tea.Cmd {
+	d.Jump.Query = query
+	d.Jump.ID++
+	timeout := DoJumpTimeout(d.Jump.ID)
+
+	drum := d.ActiveDrum()
+	if jumpMatches(drum.GetItem(drum.Active), query) == 0 {
+		// Current item is the best kind of match already
+		return timeout
+	}
+	index, ok := jumpIndex(drum.Names(), query)
+	if !ok || index == drum.Active {
+		return timeout
+	}
+	return tea.Batch(timeout, d.moveTo(index))
+}
+
+// jumpIndex returns index of the best item for query:
+// name prefix, then word prefix, then best fuzzy match
+func jumpIndex(items []string, query string) (int, bool) {
+	best, bestRank, bestScore := -1, 3, 0
+	for i, item := range items {
+		rank := jumpMatches(item, query)
+		if rank > 2 {
+			continue
+		}
+		score := 0
+		if rank == 2 {
+			score, _ = fuzzyScore(query, jumpText(item))
+		}
+		if rank < bestRank || rank == bestRank && score > bestScore {
+			best, bestRank, bestScore = i, rank, score
+		}
+	}
+	return best, best >= 0
+}
+
+// jumpMatches ranks how item matches query:
+// 0 name prefix, 1 word prefix, 2 fuzzy, 3 no match
+func jumpMatches(item, query string) int {
+	text := strings.ToLower(jumpText(item))
+	query = strings.ToLower(query)
+	switch {
+	case strings.HasPrefix(text, query):
+		return 0
+	case strings.Contains(text, " "+query):
+		return 1
+	}
+	if _, ok := fuzzyScore(query, text); ok {
+		return 2
+	}
+	return 3
+}
+
+// jumpText returns item text without the favorite star
+func jumpText(item string) string {
+	return strings.TrimPrefix(item, "★ ")
+}
+
+// countKey collects a digit of count prefix, given the action bound to it
+// Unbound digits and preset keys start a count; a preset fires when its
+// lone digit times out or no motion follows (see flushCount). Digits
+// remapped to other actions run at once
+func (d *Drums) countKey(msg tea.KeyMsg, action Action) (tea.Cmd, bool) {
+	key := msg.String()
+	if d.StoreMode || len(key) != 1 || key[0] < '0' || key[0] > '9' {
+		return nil, false
+	}
+	if len(d.Count.Keys) == 0 && (key == "0" || !startsCount(action)) {
+		return nil, false // Count starts with 1-9
+	}
+	d.Count.Keys = append(d.Count.Keys, key)
+	d.Count.ID++
+	return DoCountTimeout(d.Count.ID), true
+}
+
+// startsCount reports whether a digit bound to action may start a count
+func startsCount(action Action) bool {
+	_, preset := actionSlot(action, "preset-")
+	return action == "" || preset
+}
+
+// runCounted performs action, repeating motions by pending count prefix
+func (d *Drums) runCounted(action Action) tea.Cmd {
+	keys := d.takeCount()
+	if len(keys) == 0 {
+		return d.runAction(action)
+	}
+	if motionActions[action] {
+		count, _ := strconv.Atoi(strings.Join(keys, ""))
+		switch action {
+		case ActionMoveUp:
+			return d.moveBy(-count)
+		case ActionMoveDown:
+			return d.moveBy(count)
+		case ActionPageUp:
+			return d.moveBy(-count * d.pageSize())
+		case ActionPageDown:
+			return d.moveBy(count * d.pageSize())
+		}
+	}
+	return tea.Batch(d.flushCount(keys), d.runAction(action))
+}
+
+// takeCount returns and clears typed count digits
+func (d *Drums) takeCount() []string {
+	keys := d.Count.Keys
+	d.Count.Keys = nil
+	d.Count.ID++
+	return keys
+}
+
+// flushCount performs action of an unused lone digit (preset key)
+func (d *Drums) flushCount(keys []string) tea.Cmd {
+	if len(keys) != 1 {
+		return nil // Longer counts have no meaning of their own
+	}
+	action, _ := d.Keys.Resolve(keys)
+	return d.runAction(action)
+}
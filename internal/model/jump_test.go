@@ -0,0 +1,77 @@
+package model
+
+import (
+	"testing"
+
+	"crr/internal/data"
+	"crr/internal/store"
+)
+
+// genreDrum makes the Genre drum active
+func genreDrum(d Drums) Drums {
+	d.Active = len(d.List) - 2
+	return d
+}
+
+func TestCountPresetFiresOnTimeout(t *testing.T) {
+	d := genreDrum(newTestDrums(t))
+	d.Presets[0] = store.NewFavorite(data.Station{Name: "Jazz", Link: "http://jazz"}, "", "")
+	before := d.ActiveDrum().Active
+
+	d = send(d, key("1"))
+	if d.CurrentStreamURL != "" || len(d.Count.Keys) != 1 {
+		t.Fatalf("stored preset key: playing %q, count %q; want count pending", d.CurrentStreamURL, d.Count.Keys)
+	}
+	d = send(d, CountTimeoutMsg{ID: d.Count.ID})
+	if d.CurrentStreamURL != "http://jazz" || len(d.Count.Keys) != 0 {
+		t.Fatalf("count timeout: playing %q, count %q; want preset playing", d.CurrentStreamURL, d.Count.Keys)
+	}
+	d = send(d, key("j"))
+	if got := d.ActiveDrum().Active; got != before+1 {
+		t.Errorf("j after preset moved to %d, want %d", got, before+1)
+	}
+}
+
+func TestCountMotion(t *testing.T) {
+	tests := []struct {
+		name    string
+		presets []int // Stored preset slots
+	}{
+		{"no presets", nil},
+		{"preset of second digit", []int{1}},
+		{"all presets", []int{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := genreDrum(newTestDrums(t))
+			for _, slot := range tt.presets {
+				d.Presets[slot] = store.NewFavorite(data.Station{Name: "Jazz", Link: "http://jazz"}, "", "")
+			}
+			before := d.ActiveDrum().Active
+
+			for _, k := range []string{"1", "0", "j"} {
+				d = send(d, key(k))
+			}
+			if d.CurrentStreamURL != "" {
+				t.Errorf("digit of a count tuned to %q", d.CurrentStreamURL)
+			}
+			if got, want := d.ActiveDrum().Active, (before+10)%d.ActiveDrum().Len(); got != want {
+				t.Errorf("10j moved to %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestCountRemappedDigit(t *testing.T) {
+	d := genreDrum(newTestDrums(t))
+	km, err := NewKeyMap(map[string][]string{"help": {"5"}, "preset-5": {"f5"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Keys = km
+
+	d = send(d, key("5"))
+	if !d.ShowHelp || len(d.Count.Keys) != 0 {
+		t.Errorf("digit bound to help: help shown %v, count %q; want help at once", d.ShowHelp, d.Count.Keys)
+	}
+}
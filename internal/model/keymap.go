@@ -0,0 +1,286 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"crr/internal/store"
+)
+
+// Action is a named command that keys are bound to
+type Action string
+
+// Actions
+const (
+	ActionMoveUp         Action = "move-up"
+	ActionMoveDown       Action = "move-down"
+	ActionPrevColumn     Action = "prev-column"
+	ActionNextColumn     Action = "next-column"
+	ActionVolumeUp       Action = "volume-up"
+	ActionVolumeDown     Action = "volume-down"
+	ActionMute           Action = "mute"
+	ActionToggleDetails  Action = "toggle-details"
+	ActionToggleFavorite Action = "toggle-favorite"
+	ActionStoreMode      Action = "store-mode"
+	ActionHelp           Action = "help"
+	ActionCommand        Action = "command"
+	ActionSearch         Action = "search"
+	ActionToggleOrder    Action = "toggle-order"
+	ActionCycleSort      Action = "cycle-sort"
+	ActionCycleCodec     Action = "cycle-codec"
+	ActionCycleBitrate   Action = "cycle-bitrate"
+	ActionToggleHTTPS    Action = "toggle-https"
+	ActionClearFilters   Action = "clear-filters"
+	ActionPageUp         Action = "page-up"
+	ActionPageDown       Action = "page-down"
+	ActionHome           Action = "home"
+	ActionEnd            Action = "end"
+	ActionQuit           Action = "quit"
+)
+
+// presetAction returns action tuning to preset slot (0-based)
+func presetAction(slot int) Action {
+	return Action("preset-" + strconv.Itoa(slot+1))
+}
+
+// storePresetAction returns action storing current station in slot (0-based)
+func storePresetAction(slot int) Action {
+	return Action("store-preset-" + strconv.Itoa(slot+1))
+}
+
+// actionSlot returns preset slot (0-based) of preset action with prefix
+func actionSlot(action Action, prefix string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(string(action), prefix))
+	if !strings.HasPrefix(string(action), prefix) || err != nil || n < 1 || n > store.PresetSlots {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// actionDef describes action with its default keys
+// Keys of a chord are separated by spaces ("g g")
+type actionDef struct {
+	action Action
+	help   string   // Help text
+	group  string   // Actions with the same group share one help line
+	keys   []string // Default keys
+}
+
+// actionDefs lists all actions in help order
+var actionDefs = buildActionDefs()
+
+// buildActionDefs returns action definitions including preset slots
+func buildActionDefs() []actionDef {
+	defs := []actionDef{
+		{action: ActionMoveUp, help: "Scroll up", keys: []string{"up", "k"}},
+		{action: ActionMoveDown, help: "Scroll down", keys: []string{"down", "j"}},
+		{action: ActionPageUp, help: "Page up", keys: []string{"pgup", "ctrl+b"}},
+		{action: ActionPageDown, help: "Page down", keys: []string{"pgdown", "ctrl+f"}},
+		{action: ActionHome, help: "First item", keys: []string{"home"}},
+		{action: ActionEnd, help: "Last item", keys: []string{"end"}},
+		{action: ActionPrevColumn, help: "Previous column", keys: []string{"left", "h"}},
+		{action: ActionNextColumn, help: "Next column", keys: []string{"right", "l"}},
+		{action: ActionVolumeUp, help: "Volume up", keys: []string{"+", "="}},
+		{action: ActionVolumeDown, help: "Volume down", keys: []string{"-", "_"}},
+		{action: ActionMute, help: "Toggle mute", keys: []string{"m"}},
+		{action: ActionToggleDetails, help: "Toggle station details", keys: []string{"i"}},
+		{action: ActionToggleFavorite, help: "Star / unstar station", keys: []string{"f"}},
+	}
+	// Digits tune, shifted digits (US layout) store
+	shifted := "!@#$%^&*("
+	for slot := range store.PresetSlots {
+		defs = append(defs, actionDef{action: presetAction(slot), help: "Tune to preset", group: "preset",
+			keys: []string{strconv.Itoa(slot + 1)}})
+	}
+	for slot := range store.PresetSlots {
+		defs = append(defs, actionDef{action: storePresetAction(slot), help: "Store preset", group: "store-preset",
+			keys: []string{shifted[slot : slot+1]}})
+	}
+	defs = append(defs,
+		actionDef{action: ActionStoreMode, help: "Store mode (then digit)", keys: []string{"s"}},
+		actionDef{action: ActionCycleSort, help: "Sort stations", keys: []string{", s"}},
+		actionDef{action: ActionCycleCodec, help: "Codec filter", keys: []string{", c"}},
+		actionDef{action: ActionCycleBitrate, help: "Min bitrate filter", keys: []string{", b"}},
+		actionDef{action: ActionToggleHTTPS, help: "HTTPS only", keys: []string{", h"}},
+		actionDef{action: ActionClearFilters, help: "Clear filters", keys: []string{", x"}},
+		actionDef{action: ActionToggleOrder, help: "Sort by name / stations", keys: []string{"ctrl+o"}},
+		actionDef{action: ActionSearch, help: "Search stations", keys: []string{"/"}},
+		actionDef{action: ActionHelp, help: "Help", keys: []string{"?"}},
+		actionDef{action: ActionCommand, help: "Command palette", keys: []string{":"}},
+		actionDef{action: ActionQuit, help: "Quit", keys: []string{"q", "ctrl+c"}},
+	)
+	return defs
+}
+
+// KeyMap maps key sequences to actions
+type KeyMap struct {
+	bindings map[Action][]string // Keys per action (chords space-separated)
+}
+
+// DefaultKeyMap returns built-in bindings
+func DefaultKeyMap() *KeyMap {
+	km, _ := NewKeyMap(nil)
+	return km
+}
+
+// NewKeyMap returns default bindings with overrides applied
+// An override replaces all keys of its action; an empty list unbinds it
+func NewKeyMap(overrides map[string][]string) (*KeyMap, error) {
+	km := &KeyMap{bindings: map[Action][]string{}}
+	for _, def := range actionDefs {
+		km.bindings[def.action] = def.keys
+	}
+
+	var errs []string
+	for name, keys := range overrides {
+		action := Action(name)
+		if _, ok := km.bindings[action]; !ok {
+			errs = append(errs, fmt.Sprintf("keys: unknown action %q", name))
+			continue
+		}
+		var clean []string
+		for _, key := range keys {
+			key = strings.Join(strings.Fields(key), " ")
+			if key == "" {
+				errs = append(errs, fmt.Sprintf("keys: %s: empty key", name))
+				continue
+			}
+			clean = append(clean, key)
+		}
+		km.bindings[action] = clean
+	}
+	errs = append(errs, km.conflicts()...)
+
+	if len(errs) > 0 {
+		slices.Sort(errs)
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return km, nil
+}
+
+// conflicts reports keys bound twice and chords shadowed by a shorter binding
+func (km *KeyMap) conflicts() []string {
+	owner := map[string]Action{}
+	var errs []string
+	for _, def := range actionDefs {
+		for _, key := range km.bindings[def.action] {
+			if other, ok := owner[key]; ok && other != def.action {
+				errs = append(errs, fmt.Sprintf("keys: %q is bound to both %s and %s", key, other, def.action))
+			}
+			owner[key] = def.action
+		}
+	}
+	for key, action := range owner {
+		seq := strings.Split(key, " ")
+		for i := 1; i < len(seq); i++ {
+			prefix := strings.Join(seq[:i], " ")
+			if other, ok := owner[prefix]; ok {
+				errs = append(errs, fmt.Sprintf("keys: chord %q of %s is shadowed by %q of %s", key, action, prefix, other))
+			}
+		}
+	}
+	return errs
+}
+
+// Resolve looks up pressed key sequence
+// Returns action on exact match, or more=true if seq starts a longer chord
+func (km *KeyMap) Resolve(seq []string) (action Action, more bool) {
+	key := strings.Join(seq, " ")
+	for _, def := range actionDefs {
+		for _, k := range km.bindings[def.action] {
+			if k == key {
+				return def.action, false
+			}
+			if strings.HasPrefix(k, key+" ") {
+				more = true
+			}
+		}
+	}
+	return "", more
+}
+
+// Keys returns keys bound to action
+func (km *KeyMap) Keys(action Action) []string {
+	return km.bindings[action]
+}
+
+// HelpEntry is one line of generated help
+type HelpEntry struct {
+	Keys string // Display form of keys ("↑ / k")
+	Help string // What the keys do
+}
+
+// Help returns help for all bound actions, generated from the bindings
+// Grouped actions (presets) share one line, folded into a range ("1–9")
+// while each has one key and the keys are a run of a key row
+func (km *KeyMap) Help() []HelpEntry {
+	var out []HelpEntry
+	for i := 0; i < len(actionDefs); i++ {
+		def := actionDefs[i]
+		keys := km.bindings[def.action]
+		if def.group != "" {
+			// Collect keys of the whole group
+			single := len(keys) == 1
+			for i+1 < len(actionDefs) && actionDefs[i+1].group == def.group {
+				i++
+				more := km.bindings[actionDefs[i].action]
+				single = single && len(more) == 1
+				keys = append(keys[:len(keys):len(keys)], more...)
+			}
+			if single {
+				if run, ok := keyRun(keys); ok {
+					out = append(out, HelpEntry{Keys: run, Help: def.help})
+					continue
+				}
+			}
+		}
+
+		if len(keys) == 0 {
+			continue // Unbound
+		}
+		display := make([]string, len(keys))
+		for k, key := range keys {
+			display[k] = displayKey(key)
+		}
+		out = append(out, HelpEntry{Keys: strings.Join(display, " / "), Help: def.help})
+	}
+	return out
+}
+
+// keyRows are rows of keys whose runs fold into a range in help
+var keyRows = []string{"1234567890", "!@#$%^&*()", "abcdefghijklmnopqrstuvwxyz"}
+
+// keyRun folds keys into "first–last" if they are a run of a key row
+func keyRun(keys []string) (string, bool) {
+	run := strings.Join(keys, "")
+	if len(keys) < 3 || len(run) != len(keys) {
+		return "", false // Too short to fold, or named keys and chords
+	}
+	for _, row := range keyRows {
+		if strings.Contains(row, run) {
+			return keys[0] + "–" + keys[len(keys)-1], true
+		}
+	}
+	return "", false
+}
+
+// keySymbols are display forms of named keys
+var keySymbols = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
+
+// displayKey returns key (or chord) in display form
+func displayKey(key string) string {
+	parts := strings.Split(key, " ")
+	for i, p := range parts {
+		if sym, ok := keySymbols[p]; ok {
+			parts[i] = sym
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,35 @@
+package model
+
+import "testing"
+
+func TestHelpPresetKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		tune      string // Keys of "Tune to preset"
+		store     string // Keys of "Store preset"
+	}{
+		{"defaults", nil, "1–9", "!–("},
+		{"remapped preset", map[string][]string{"preset-5": {"p"}}, "1 / 2 / 3 / 4 / p / 6 / 7 / 8 / 9", "!–("},
+		{"preset with two keys", map[string][]string{"preset-1": {"1", "f1"}}, "1 / f1 / 2 / 3 / 4 / 5 / 6 / 7 / 8 / 9", "!–("},
+		{"shifted run", map[string][]string{"store-preset-1": {"@"}, "store-preset-2": {"!"}}, "1–9", "@ / ! / # / $ / % / ^ / & / * / ("},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km, err := NewKeyMap(tt.overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]string{}
+			for _, e := range km.Help() {
+				got[e.Help] = e.Keys
+			}
+			if got["Tune to preset"] != tt.tune {
+				t.Errorf("Tune to preset keys %q, want %q", got["Tune to preset"], tt.tune)
+			}
+			if got["Store preset"] != tt.store {
+				t.Errorf("Store preset keys %q, want %q", got["Store preset"], tt.store)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crr/internal/data"
+	"crr/internal/store"
+)
+
+// Palette is the ":" command line
+type Palette struct {
+	Active  bool     // Palette is open and takes keys
+	Input   string   // Typed command
+	Matches []string // Completion candidates for the word being typed
+	Message string   // Result or error of the last command (shown until next key)
+
+	cycle    []string // Candidates being cycled with Tab
+	cycleIdx int      // Next candidate in cycle
+	cycleAt  string   // Input before the cycled word
+}
+
+// Open clears palette and starts input
+func (p *Palette) Open() {
+	*p = Palette{Active: true}
+}
+
+// command is a palette command
+type command struct {
+	name     string
+	usage    string                                      // Argument description
+	help     string                                      // What the command does
+	complete func(d *Drums) []string                     // Argument candidates (nil: none)
+	run      func(d *Drums, arg string) (tea.Cmd, error) // Performs command
+}
+
+// commands returns palette commands: argument commands first, then every action
+func commands() []command {
+	cmds := []command{
+		{"continent", "NAME", "Select continent", columnCandidates(ColumnContinent), runColumn(ColumnContinent)},
+		{"country", "CODE|NAME", "Select country", columnCandidates(ColumnCountry), runColumn(ColumnCountry)},
+		{"state", "NAME|All", "Select state or region", columnCandidates(ColumnState), runColumn(ColumnState)},
+		{"language", "NAME", "Select language", columnCandidates(ColumnLanguage), runColumn(ColumnLanguage)},
+		{"genre", "NAME", "Select genre", columnCandidates(ColumnTag), runColumn(ColumnTag)},
+		{"play", "URL", "Play stream URL", nil, runPlay},
+		{"volume", "0-100", "Set volume", nil, runVolume},
+		{"preset", "1-9", "Tune to preset", presetCandidates, runPreset},
+		{"sort", "clicks|votes|name|bitrate|changed", "Sort stations", sortCandidates, runSort},
+		{"codec", "NAME|any", "Only stations with codec", codecCandidates, runCodec},
+		{"bitrate", "KBPS|any", "Only stations with at least bitrate", bitrateCandidates, runBitrate},
+		{"https", "on|off", "Only HTTPS streams", switchCandidates, runHTTPS},
+		{"lang", "NAME|any", "Only stations in language", nil, runLanguage},
+	}
+	for _, def := range actionDefs {
+		if def.group != "" || def.action == ActionCommand {
+			continue // Presets have :preset, palette is already open
+		}
+		action := def.action
+		cmds = append(cmds, command{name: string(action), help: def.help, run: func(d *Drums, arg string) (tea.Cmd, error) {
+			return d.runAction(action), nil
+		}})
+	}
+	return cmds
+}
+
+// findCommand returns command by name
+func findCommand(name string) (command, bool) {
+	for _, c := range commands() {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// paletteKey handles key while palette is open
+func (d *Drums) paletteKey(msg tea.KeyMsg) tea.Cmd {
+	p := &d.Palette
+	if msg.Type != tea.KeyTab {
+		p.cycle = nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		p.Active = false
+		return nil
+	case tea.KeyEnter:
+		p.Active = false
+		cmd, err := d.execute(p.Input)
+		if err != nil {
+			p.Message = err.Error()
+		}
+		return cmd
+	case tea.KeyTab:
+		d.complete()
+	case tea.KeyBackspace:
+		if p.Input == "" {
+			p.Active = false
+			return nil
+		}
+		runes := []rune(p.Input)
+		p.Input = string(runes[:len(runes)-1])
+	case tea.KeySpace:
+		p.Input += " "
+	case tea.KeyRunes:
+		p.Input += string(msg.Runes)
+	}
+	_, _, p.Matches = d.completions()
+	return nil
+}
+
+// execute runs command line through the same paths as keys
+func (d *Drums) execute(line string) (tea.Cmd, error) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if name == "" {
+		return nil, nil
+	}
+	cmd, ok := findCommand(strings.ToLower(name))
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q (press ? for help)", name)
+	}
+	arg = strings.TrimSpace(arg)
+	if cmd.usage != "" && arg == "" {
+		return nil, fmt.Errorf("usage: :%s %s", cmd.name, cmd.usage)
+	}
+	return cmd.run(d, arg)
+}
+
+// completions returns input before the word being typed, the word and its candidates
+func (d *Drums) completions() (base, word string, candidates []string) {
+	input := d.Palette.Input
+	name, arg, hasArg := strings.Cut(input, " ")
+	if !hasArg {
+		var names []string
+		for _, c := range commands() {
+			names = append(names, c.name)
+		}
+		return "", name, matchPrefix(names, name)
+	}
+
+	cmd, ok := findCommand(strings.ToLower(name))
+	if !ok || cmd.complete == nil {
+		return input, "", nil
+	}
+	arg = strings.TrimLeft(arg, " ")
+	base = input[:len(input)-len(arg)]
+	return base, arg, matchPrefix(cmd.complete(d), arg)
+}
+
+// complete extends input with the common prefix of candidates,
+// or cycles through them when there is nothing more to add
+func (d *Drums) complete() {
+	p := &d.Palette
+	if p.cycle != nil {
+		p.Input = p.cycleAt + p.cycle[p.cycleIdx%len(p.cycle)]
+		p.cycleIdx++
+		return
+	}
+
+	base, word, candidates := d.completions()
+	switch {
+	case len(candidates) == 0:
+		return
+	case len(candidates) == 1:
+		p.Input = base + candidates[0]
+		if base == "" {
+			if c, ok := findCommand(candidates[0]); ok && c.usage != "" {
+				p.Input += " " // Ready for argument
+			}
+		}
+	default:
+		if prefix := commonPrefix(candidates); len(prefix) > len(word) {
+			p.Input = base + prefix
+			return
+		}
+		p.cycle, p.cycleIdx, p.cycleAt = candidates, 1, base
+		p.Input = base + candidates[0]
+	}
+}
+
+// matchPrefix returns items starting with prefix (any case),
+// or containing it if none starts with it
+func matchPrefix(items []string, prefix string) []string {
+	lower := strings.ToLower(prefix)
+	var starts, contains []string
+	for _, it := range items {
+		l := strings.ToLower(it)
+		switch {
+		case strings.HasPrefix(l, lower):
+			starts = append(starts, it)
+		case strings.Contains(l, lower):
+			contains = append(contains, it)
+		}
+	}
+	if len(starts) > 0 {
+		return starts
+	}
+	return contains
+}
+
+// commonPrefix returns longest common prefix of items (case-sensitive)
+func commonPrefix(items []string) string {
+	prefix := items[0]
+	for _, it := range items[1:] {
+		for !strings.HasPrefix(it, prefix) {
+			_, size := lastRune(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// lastRune returns last rune of s and its size in bytes
+func lastRune(s string) (rune, int) {
+	r := []rune(s)
+	last := r[len(r)-1]
+	return last, len(string(last))
+}
+
+// columnCandidates returns completion of drum of column kind: entries and their codes
+func columnCandidates(kind string) func(d *Drums) []string {
+	return func(d *Drums) []string {
+		i := d.columnIndex(kind)
+		if i < 0 {
+			return nil
+		}
+		out := d.List[i].Names()
+		for _, it := range d.List[i].Items {
+			if it.Value.Code != "" {
+				out = append(out, it.Value.Code)
+			}
+		}
+		return out
+	}
+}
+
+// presetCandidates returns stored preset numbers
+func presetCandidates(d *Drums) []string {
+	var out []string
+	for i, p := range d.Presets {
+		if p.URL != "" {
+			out = append(out, strconv.Itoa(i+1))
+		}
+	}
+	return out
+}
+
+// findItem returns drum item matching arg: exact (any case), then unique match
+func findItem(items []string, arg string) (string, error) {
+	for _, it := range items {
+		if strings.EqualFold(it, arg) {
+			return it, nil
+		}
+	}
+	matches := matchPrefix(items, arg)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no match for %q", arg)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%q is ambiguous: %s", arg, strings.Join(matches, ", "))
+	}
+}
+
+// runColumn returns command selecting entry (or code) of drum of column kind
+// like scrolling the drum
+func runColumn(kind string) func(d *Drums, arg string) (tea.Cmd, error) {
+	return func(d *Drums, arg string) (tea.Cmd, error) {
+		i := d.columnIndex(kind)
+		if i < 0 {
+			return nil, fmt.Errorf("no %s drum (see columns in config)", kind)
+		}
+		drum := &d.List[i]
+		index := slices.IndexFunc(drum.Items, func(it Item) bool {
+			return it.Value.Code != "" && strings.EqualFold(it.Value.Code, arg)
+		})
+		if index < 0 {
+			name, err := findItem(drum.Names(), arg)
+			if err != nil {
+				return nil, err
+			}
+			index = slices.IndexFunc(drum.Items, func(it Item) bool { return it.Name == name })
+		}
+		old := d.selection()
+		drum.Active = index
+		d.ScrollOffset = 0
+		return d.changeColumn(i, old), nil
+	}
+}
+
+// runPlay switches to stream URL (or playlist) with the usual chunk transition
+func runPlay(d *Drums, arg string) (tea.Cmd, error) {
+	if !strings.HasPrefix(arg, "http://") && !strings.HasPrefix(arg, "https://") {
+		return nil, fmt.Errorf("not an http(s) URL: %q", arg)
+	}
+	return DoResolveStream(data.Station{Name: arg, Link: arg}), nil
+}
+
+// runVolume sets volume level
+func runVolume(d *Drums, arg string) (tea.Cmd, error) {
+	level, err := strconv.Atoi(strings.TrimSuffix(arg, "%"))
+	if err != nil || level < 0 || level > 100 {
+		return nil, fmt.Errorf("volume must be 0-100, got %q", arg)
+	}
+	d.Volume.SetLevel(level)
+	d.applyVolume()
+	return nil, nil
+}
+
+// runPreset tunes to preset like its key
+func runPreset(d *Drums, arg string) (tea.Cmd, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > store.PresetSlots {
+		return nil, fmt.Errorf("preset must be 1-%d, got %q", store.PresetSlots, arg)
+	}
+	if d.Presets[n-1].URL == "" {
+		return nil, fmt.Errorf("preset %d is empty", n)
+	}
+	return d.runAction(presetAction(n - 1)), nil
+}
@@ -0,0 +1,267 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crr/internal/data"
+	"crr/internal/provider"
+)
+
+// Search is the "/" station search across all countries
+type Search struct {
+	Active  bool           // Search input takes keys
+	Shown   bool           // Station drum shows search results
+	Query   string         // Typed query
+	Sent    string         // Query of Results (or of the request in flight)
+	Results []data.Station // Stations found for Sent
+	ID      int            // Current debounce timer ID
+	Loading bool           // Request in flight
+	Err     error          // Error of the last request
+
+	savedStations []data.Station // Station list before search (restored on cancel)
+	savedDrum     Drum           // Station drum before search
+	savedMore     bool           // More pages existed before search
+}
+
+// parseQuery splits search text into name and optional tag:/lang: filters
+func parseQuery(text string) provider.SearchQuery {
+	var q provider.SearchQuery
+	var name []string
+	for _, word := range strings.Fields(text) {
+		key, value, ok := strings.Cut(word, ":")
+		switch {
+		case ok && key == "tag":
+			q.Tag = value
+		case ok && (key == "lang" || key == "language"):
+			q.Language = value
+		default:
+			name = append(name, word)
+		}
+	}
+	q.Name = strings.Join(name, " ")
+	return q
+}
+
+// openSearch starts search input in Station drum
+// Current station list is kept to be restored on cancel; a station list
+// still loading fills the kept one (see behindSearch)
+func (d *Drums) openSearch() {
+	if !d.Search.Shown {
+		d.Search = Search{
+			Shown:         true,
+			savedStations: d.Stations,
+			savedDrum:     *d.StationDrum(),
+			savedMore:     d.MoreStations,
+		}
+		d.MoreStations = false
+	}
+	d.Search.Active = true
+	d.Active = len(d.List) - 1
+	d.ScrollOffset = 0
+	d.filterSearch()
+}
+
+// closeSearch forgets search, Station drum is refilled by caller
+func (d *Drums) closeSearch() {
+	if !d.Search.Shown {
+		return
+	}
+	d.stopSearch()
+	d.Search = Search{}
+	d.updateStationTitle()
+}
+
+// dismissSearch closes search and puts previous station list back
+func (d *Drums) dismissSearch() {
+	stations, drum, more := d.Search.savedStations, d.Search.savedDrum, d.Search.savedMore
+	d.closeSearch()
+	d.Stations = stations
+	d.MoreStations = more
+	*d.StationDrum() = drum
+	d.ScrollOffset = 0
+}
+
+// behindSearch runs fn on the station list kept by search (on the shown
+// list if search is closed), so stations loaded meanwhile are there on cancel
+func (d *Drums) behindSearch(fn func() tea.Cmd) tea.Cmd {
+	s := &d.Search
+	if !s.Shown {
+		return fn()
+	}
+	stations, drum, more := d.Stations, *d.StationDrum(), d.MoreStations
+	d.Stations, *d.StationDrum(), d.MoreStations = s.savedStations, s.savedDrum, s.savedMore
+	cmd := fn()
+	s.savedStations, s.savedDrum, s.savedMore = d.Stations, *d.StationDrum(), d.MoreStations
+	d.Stations, *d.StationDrum(), d.MoreStations = stations, drum, more
+	return cmd
+}
+
+// searchKey handles key while search input is open
+func (d *Drums) searchKey(msg tea.KeyMsg) tea.Cmd {
+	s := &d.Search
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		d.dismissSearch()
+		return nil
+	case tea.KeyEnter:
+		// Results stay in Station drum and play like any station list
+		s.Active = false
+		st, ok := d.CurrentStation()
+		if !ok || st.Link == d.CurrentStreamURL {
+			return nil
+		}
+		d.tuneTo(st)
+		d.KeepStream = true // A list still loading behind search must not replace it
+		return DoSwitchStation(d.Player, st.Link)
+	case tea.KeyUp, tea.KeyCtrlP:
+		d.StationDrum().MoveUp()
+		d.ScrollOffset = 0
+		return nil
+	case tea.KeyDown, tea.KeyCtrlN:
+		d.StationDrum().MoveDown()
+		d.ScrollOffset = 0
+		return nil
+	case tea.KeyBackspace:
+		if s.Query == "" {
+			return nil
+		}
+		runes := []rune(s.Query)
+		s.Query = string(runes[:len(runes)-1])
+	case tea.KeySpace:
+		s.Query += " "
+	case tea.KeyRunes:
+		s.Query += string(msg.Runes)
+	default:
+		return nil
+	}
+
+	// Filter what is already found, ask the server once typing pauses
+	d.filterSearch()
+	s.ID++
+	return DoSearchDebounce(s.ID)
+}
+
+// startSearch sends query unless its results are already shown
+func (d *Drums) startSearch() tea.Cmd {
+	s := &d.Search
+	q := parseQuery(s.Query)
+	if q.IsEmpty() || s.Query == s.Sent {
+		return nil
+	}
+	d.stopSearch()
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancelSearch = cancel
+	s.Sent = s.Query
+	s.Loading = true
+	s.Err = nil
+	d.filterSearch()
+	return DoSearchStations(ctx, s.Sent, q, d.Filter)
+}
+
+// stopSearch cancels search request in flight (if any)
+func (d *Drums) stopSearch() {
+	if d.cancelSearch != nil {
+		d.cancelSearch()
+		d.cancelSearch = nil
+	}
+}
+
+// filterSearch fills Station drum with results fuzzy-matching typed name
+// The selected station stays selected if it still matches
+func (d *Drums) filterSearch() {
+	s := &d.Search
+	selected, _ := d.CurrentStation()
+
+	d.Stations = fuzzyFilter(s.Results, parseQuery(s.Query).Name)
+	d.StationDrum().Title = fmt.Sprintf("Search (%d/%d)", len(d.Stations), len(s.Results))
+	if f := d.Filter.String(); f != "" {
+		d.StationDrum().Title = fmt.Sprintf("Search (%d/%d, %s)", len(d.Stations), len(s.Results), f)
+	}
+	if len(d.Stations) == 0 {
+		d.StationDrum().Items = textItems(searchPlaceholder(s))
+		d.StationDrum().Active = 0
+		return
+	}
+
+	names := make([]string, len(d.Stations))
+	active := 0
+	for i, st := range d.Stations {
+		names[i] = d.stationLabel(st)
+		if st.Link == selected.Link {
+			active = i
+		}
+	}
+	d.StationDrum().Items = textItems(names...)
+	d.StationDrum().Active = active
+}
+
+// searchPlaceholder returns Station drum text when nothing matches
+func searchPlaceholder(s *Search) string {
+	switch {
+	case s.Loading:
+		return "Searching..."
+	case s.Err != nil:
+		return "Search failed"
+	case s.Query == "":
+		return "Type to search"
+	default:
+		return "No matches"
+	}
+}
+
+// fuzzyFilter returns stations whose names contain pattern letters in order,
+// best matches first (equal scores keep server order, most clicked first)
+func fuzzyFilter(stations []data.Station, pattern string) []data.Station {
+	if strings.TrimSpace(pattern) == "" {
+		return stations
+	}
+	type match struct {
+		station data.Station
+		score   int
+	}
+	var matches []match
+	for _, st := range stations {
+		if score, ok := fuzzyScore(pattern, st.Name); ok {
+			matches = append(matches, match{st, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	out := make([]data.Station, len(matches))
+	for i, m := range matches {
+		out[i] = m.station
+	}
+	return out
+}
+
+// fuzzyScore matches pattern as a case-insensitive subsequence of text
+// Consecutive letters and letters at word starts score higher
+func fuzzyScore(pattern, text string) (int, bool) {
+	p := []rune(strings.ToLower(strings.ReplaceAll(pattern, " ", "")))
+	t := []rune(strings.ToLower(text))
+
+	score, pi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 2 // Consecutive
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3 // Word start
+		}
+		prev = ti
+		pi++
+	}
+	return score, pi == len(p)
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crr/internal/data"
+	"crr/internal/player"
+)
+
+// newTestDrums returns drums with the default layout and a silent player,
+// keeping config and cache files in temporary directories
+func newTestDrums(t *testing.T) Drums {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	pl := player.New(player.NewNull(), t.TempDir())
+	t.Cleanup(pl.Cleanup)
+	columns, err := NewColumns(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *NewDrums(pl, DefaultKeyMap(), columns)
+}
+
+// send passes msg to Update, dropping commands (nothing runs in background)
+func send(d Drums, msg tea.Msg) Drums {
+	m, _ := d.Update(msg)
+	return m.(Drums)
+}
+
+// key returns key message for a key name ("/", "esc", "down")
+func key(name string) tea.KeyMsg {
+	switch name {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// startLoading changes the Genre selection, leaving station loading pending
+func startLoading(t *testing.T, d Drums) Drums {
+	t.Helper()
+	d.Active = len(d.List) - 2
+	d = send(d, key("down"))
+	if got := d.StationDrum().GetItem(0); got != "Scanning..." {
+		t.Fatalf("Station drum shows %q while loading, want Scanning...", got)
+	}
+	return send(d, FetchDebounceMsg{ID: d.DebounceID})
+}
+
+// loaded returns stations answering the pending station request of d
+func loaded(d Drums) CachedStationsMsg {
+	return CachedStationsMsg{
+		ID:       d.DebounceID,
+		Query:    d.query(),
+		Stations: []data.Station{{Name: "One", Link: "http://one"}, {Name: "Two", Link: "http://two"}},
+		Pages:    1,
+		Fresh:    true,
+	}
+}
+
+func TestSearchEscDuringLoad(t *testing.T) {
+	d := startLoading(t, newTestDrums(t))
+	msg := loaded(d)
+
+	d = send(d, key("/"))
+	d = send(d, key("esc"))
+	if d.Search.Shown {
+		t.Fatal("search still shown after esc")
+	}
+	d = send(d, msg)
+	if got := d.StationDrum().GetItem(0); got != "One" {
+		t.Errorf("Station drum shows %q after load, want One", got)
+	}
+}
+
+func TestSearchLoadBehindSearch(t *testing.T) {
+	d := startLoading(t, newTestDrums(t))
+
+	d = send(d, key("/"))
+	d = send(d, loaded(d))
+	if got := d.StationDrum().GetItem(0); got != "Type to search" {
+		t.Errorf("Station drum shows %q during search, want Type to search", got)
+	}
+	d = send(d, key("esc"))
+	if len(d.Stations) != 2 || d.StationDrum().GetItem(0) != "One" {
+		t.Errorf("Station drum shows %q (%d stations) after esc, want loaded list",
+			d.StationDrum().GetItem(0), len(d.Stations))
+	}
+}
+
+func TestSearchEscAfterEnter(t *testing.T) {
+	d := startLoading(t, newTestDrums(t))
+	d = send(d, loaded(d))
+
+	d = send(d, key("/"))
+	d = send(d, key("jazz"))
+	d = send(d, SearchDebounceMsg{ID: d.Search.ID})
+	d = send(d, SearchStationsMsg{Query: "jazz", Stations: []data.Station{{Name: "Jazz", Link: "http://jazz"}}})
+	d = send(d, key("enter"))
+	if d.CurrentStreamURL != "http://jazz" || !d.Search.Shown {
+		t.Fatalf("enter: playing %q, search shown %v; want search result playing", d.CurrentStreamURL, d.Search.Shown)
+	}
+
+	d = send(d, key("esc"))
+	if d.Search.Shown || d.StationDrum().GetItem(0) != "One" {
+		t.Errorf("esc after enter: search shown %v, Station drum shows %q; want previous list",
+			d.Search.Shown, d.StationDrum().GetItem(0))
+	}
+	if d.CurrentStreamURL != "http://jazz" {
+		t.Errorf("esc after enter stopped search result, playing %q", d.CurrentStreamURL)
+	}
+}
@@ -2,20 +2,26 @@ package model
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
-	"crr/internal/client"
+	"crr/internal/cache"
 	"crr/internal/data"
+	"crr/internal/logger"
 	"crr/internal/player"
+	"crr/internal/provider"
 )
 
 // TickMsg is a timer message for marquee animation
 type TickMsg time.Time
 
-// TickInterval is the marquee update interval
-const TickInterval = 300 * time.Millisecond
+// TickInterval is the marquee update interval (set from config)
+var TickInterval = 300 * time.Millisecond
 
 // DoTick creates a timer command
 func DoTick() tea.Cmd {
@@ -37,8 +43,8 @@ func DoClockTick() tea.Cmd {
 	})
 }
 
-// FetchDebounceInterval is the delay before station request
-const FetchDebounceInterval = 3 * time.Second
+// FetchDebounceInterval is the delay before station request (set from config)
+var FetchDebounceInterval = 3 * time.Second
 
 // FetchDebounceMsg is a debounce timer message
 type FetchDebounceMsg struct {
@@ -52,17 +58,230 @@ func DoFetchDebounce(id int) tea.Cmd {
 	})
 }
 
+// CountTimeout is how long a typed digit waits for a motion key
+// before it is taken as a preset key
+var CountTimeout = 500 * time.Millisecond
+
+// CountTimeoutMsg ends a count prefix
+type CountTimeoutMsg struct {
+	ID int // ID for validity check
+}
+
+// DoCountTimeout creates a count prefix timer command
+func DoCountTimeout(id int) tea.Cmd {
+	return tea.Tick(CountTimeout, func(t time.Time) tea.Msg {
+		return CountTimeoutMsg{ID: id}
+	})
+}
+
+// JumpTimeout is the typing pause that ends type-to-jump
+var JumpTimeout = 1 * time.Second
+
+// JumpTimeoutMsg ends type-to-jump
+type JumpTimeoutMsg struct {
+	ID int // ID for validity check
+}
+
+// DoJumpTimeout creates a type-to-jump timer command
+func DoJumpTimeout(id int) tea.Cmd {
+	return tea.Tick(JumpTimeout, func(t time.Time) tea.Msg {
+		return JumpTimeoutMsg{ID: id}
+	})
+}
+
+// DirectoryMsg contains live country, tag and language lists
+type DirectoryMsg struct {
+	Directory data.Directory
+	Err       error
+}
+
+// DoLoadDirectory creates a command loading country, tag and language lists
+// A fresh cache entry is used as is; otherwise lists are fetched and cached,
+// falling back to a stale entry when the API is unreachable
+func DoLoadDirectory() tea.Cmd {
+	return func() tea.Msg {
+		cached, fresh, err := cache.LoadDirectory()
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Log.Printf("Load directory cache: %v", err)
+		}
+		if fresh {
+			return DirectoryMsg{Directory: cached}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+		defer cancel()
+		dir, err := Provider.Categories(ctx)
+		if err != nil {
+			if len(cached.Countries) > 0 || len(cached.Tags) > 0 {
+				return DirectoryMsg{Directory: cached} // Stale beats static
+			}
+			return DirectoryMsg{Err: fmt.Errorf("load directory: %w", err)}
+		}
+
+		if err := cache.SaveDirectory(dir); err != nil {
+			logger.Log.Printf("Save directory cache: %v", err)
+		}
+		return DirectoryMsg{Directory: dir}
+	}
+}
+
+// StatesMsg contains states and regions of a country
+type StatesMsg struct {
+	Country string // Country code of the request
+	States  []data.Category
+	Err     error
+}
+
+// DoLoadStates creates a command loading states of country (code and name)
+func DoLoadStates(code, country string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+		defer cancel()
+		states, err := Provider.States(ctx, country)
+		return StatesMsg{Country: code, States: states, Err: err}
+	}
+}
+
+// SearchDebounceInterval is the typing pause before a search request
+var SearchDebounceInterval = 400 * time.Millisecond
+
+// SearchDebounceMsg is a search debounce timer message
+type SearchDebounceMsg struct {
+	ID int // ID for validity check
+}
+
+// DoSearchDebounce creates a search debounce timer command
+func DoSearchDebounce(id int) tea.Cmd {
+	return tea.Tick(SearchDebounceInterval, func(t time.Time) tea.Msg {
+		return SearchDebounceMsg{ID: id}
+	})
+}
+
+// SearchStationsMsg contains global search result
+type SearchStationsMsg struct {
+	Query    string // Query text of the request (stale results are dropped)
+	Stations []data.Station
+	Err      error
+}
+
+// DoSearchStations creates a global station search command
+// The request is aborted when ctx is cancelled
+func DoSearchStations(ctx context.Context, text string, q provider.SearchQuery, f provider.Filter) tea.Cmd {
+	return func() tea.Msg {
+		stations, err := Provider.Search(ctx, q, f)
+		return SearchStationsMsg{Query: text, Stations: stations, Err: err}
+	}
+}
+
+// Provider is the station directory browsed by the drums (set from config)
+var Provider provider.StationProvider = provider.RadioBrowser{}
+
 // FetchStationsMsg contains station loading result
 type FetchStationsMsg struct {
+	ID       int // DebounceID of the request (stale results are dropped)
 	Stations []data.Station
+	More     bool // Next page may have stations
+	Err      error
+}
+
+// DoFetchStations creates a command loading the first page of stations
+// The request is aborted when ctx is cancelled
+// Successful results are saved to the station cache
+func DoFetchStations(ctx context.Context, id int, q provider.StationQuery, f provider.Filter) tea.Cmd {
+	return func() tea.Msg {
+		stations, more, err := Provider.Stations(ctx, q, 0, f)
+		if err == nil && len(stations) > 0 {
+			if err := cache.SaveStations(q.Key(), f.Key(), stations, 1); err != nil {
+				logger.Log.Printf("Save station cache: %v", err)
+			}
+		}
+		return FetchStationsMsg{ID: id, Stations: stations, More: more, Err: err}
+	}
+}
+
+// FetchMoreMsg contains loaded stations extended by the next page
+type FetchMoreMsg struct {
+	ID       int            // DebounceID of the request (stale results are dropped)
+	Stations []data.Station // Loaded stations followed by new ones
+	More     bool           // Next page may have stations
 	Err      error
 }
 
-// DoFetchStations creates a station loading command
-func DoFetchStations(country, genre string) tea.Cmd {
+// DoFetchMoreStations creates a command loading page (0-based) after loaded stations
+// Stations already loaded are skipped (the order may shift between requests)
+// and the whole list is saved to the station cache
+func DoFetchMoreStations(ctx context.Context, id int, q provider.StationQuery, f provider.Filter, loaded []data.Station, page int) tea.Cmd {
+	return func() tea.Msg {
+		next, more, err := Provider.Stations(ctx, q, page, f)
+		if err != nil {
+			return FetchMoreMsg{ID: id, Err: err}
+		}
+
+		seen := make(map[string]bool, len(loaded))
+		for _, s := range loaded {
+			seen[provider.StreamKey(s.Link)] = true
+		}
+		stations := slices.Clip(loaded)
+		for _, s := range next {
+			if key := provider.StreamKey(s.Link); !seen[key] {
+				seen[key] = true
+				stations = append(stations, s)
+			}
+		}
+		if err := cache.SaveStations(q.Key(), f.Key(), stations, page+1); err != nil {
+			logger.Log.Printf("Save station cache: %v", err)
+		}
+		return FetchMoreMsg{ID: id, Stations: stations, More: more}
+	}
+}
+
+// CachedStationsMsg contains stations read from the on-disk cache
+type CachedStationsMsg struct {
+	ID       int                   // DebounceID of the request (stale results are dropped)
+	Query    provider.StationQuery // Selection of the request
+	Stations []data.Station
+	Pages    int  // Pages the stations were loaded in (0 if unknown)
+	Fresh    bool // Entry is younger than cache.StationsTTL
+}
+
+// DoLoadCachedStations creates a command reading stations cached for filter f
+func DoLoadCachedStations(id int, q provider.StationQuery, f provider.Filter) tea.Cmd {
 	return func() tea.Msg {
-		stations, err := client.GetStations(context.Background(), country, genre)
-		return FetchStationsMsg{Stations: stations, Err: err}
+		stations, pages, fresh, err := cache.LoadStations(q.Key(), f.Key())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Log.Printf("Load station cache: %v", err)
+		}
+		return CachedStationsMsg{ID: id, Query: q, Stations: stations, Pages: pages, Fresh: fresh}
+	}
+}
+
+// ResolveStreamMsg contains the stream URL to play for a station
+type ResolveStreamMsg struct {
+	Station data.Station // Station with resolved Link
+	Err     error
+}
+
+// DoResolveStream creates a command resolving stream URL of st with Provider
+// (playlists are expanded)
+func DoResolveStream(st data.Station) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		link, err := Provider.Resolve(ctx, st)
+		st.Link = link
+		return ResolveStreamMsg{Station: st, Err: err}
+	}
+}
+
+// ShowFavoritesMsg asks to fill Station drum with favorites
+type ShowFavoritesMsg struct {
+	ID int // DebounceID when requested
+}
+
+// DoShowFavorites creates a command showing favorites (no network)
+func DoShowFavorites(id int) tea.Cmd {
+	return func() tea.Msg {
+		return ShowFavoritesMsg{ID: id}
 	}
 }
 
@@ -107,36 +326,36 @@ func DoSwitchStation(p *player.Player, url string) tea.Cmd {
 	}
 }
 
-// MetadataTickMsg is a timer message for metadata updates
-type MetadataTickMsg time.Time
-
-// MetadataTickInterval is the metadata polling interval (2 seconds)
-const MetadataTickInterval = 2 * time.Second
-
-// DoMetadataTick creates a metadata timer command
-func DoMetadataTick() tea.Cmd {
-	return tea.Tick(MetadataTickInterval, func(t time.Time) tea.Msg {
-		return MetadataTickMsg(t)
-	})
-}
-
-// MetadataMsg contains metadata fetch result
+// MetadataMsg contains track info pushed by the backend
 type MetadataMsg struct {
+	URL    string // Stream the track belongs to
 	Title  string
 	Artist string
-	Err    error
 }
 
-// DoFetchMetadata creates a command to fetch stream metadata
-func DoFetchMetadata(url string) tea.Cmd {
+// DoWaitMetadata creates a command that waits for the next track change
+func DoWaitMetadata(ch <-chan player.TrackInfo) tea.Cmd {
 	return func() tea.Msg {
-		if url == "" {
-			return MetadataMsg{Err: nil}
+		info, ok := <-ch
+		if !ok {
+			return nil
 		}
-		info, err := player.GetStreamMetadata(url)
-		if err != nil {
-			return MetadataMsg{Err: err}
+		return MetadataMsg{URL: info.URL, Title: info.Title, Artist: info.Artist}
+	}
+}
+
+// PlayerStateMsg contains playback state change of the current stream
+type PlayerStateMsg struct {
+	player.StateEvent
+}
+
+// DoWaitState creates a command that waits for the next playback state change
+func DoWaitState(ch <-chan player.StateEvent) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-ch
+		if !ok {
+			return nil
 		}
-		return MetadataMsg{Title: info.Title, Artist: info.Artist}
+		return PlayerStateMsg{ev}
 	}
 }
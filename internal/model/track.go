@@ -4,6 +4,8 @@ package model
 type Track struct {
 	Name   string // Track title
 	Artist string // Artist name
+	Status string // Playback status shown instead of track (empty when playing)
+	Detail string // Second status line (station name or error)
 }
 
 // NewTrack creates a new Track instance
@@ -14,10 +16,18 @@ func NewTrack() *Track {
 	}
 }
 
-// SetTrack sets track information
+// SetTrack sets track information (clears playback status)
 func (t *Track) SetTrack(name, artist string) {
 	t.Name = name
 	t.Artist = artist
+	t.Status = ""
+	t.Detail = ""
+}
+
+// SetStatus shows playback status instead of track
+func (t *Track) SetStatus(status, detail string) {
+	t.Status = status
+	t.Detail = detail
 }
 
 // DisplayName returns formatted track display name
@@ -33,6 +43,10 @@ func (t *Track) DisplayName() string {
 
 // DisplayLines returns artist and title as two separate lines
 func (t *Track) DisplayLines() (string, string) {
+	// Connection problems take precedence over stale track info
+	if t.Status != "" {
+		return t.Status, t.Detail
+	}
 	// If artist is empty - metadata is being scanned
 	if t.Artist == "" {
 		return "SCANNING...", ""
@@ -1,10 +1,19 @@
 package model
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 
+	"crr/internal/data"
 	"crr/internal/logger"
 	"crr/internal/player"
+	"crr/internal/provider"
+	"crr/internal/store"
 )
 
 // Update handles events (required by tea.Model interface)
@@ -28,19 +37,19 @@ func (d Drums) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		d.Clock.Update()
 		return d, DoClockTick() // Continue clock updates
 
-	case MetadataTickMsg:
-		// Request current stream metadata
-		return d, tea.Batch(
-			DoMetadataTick(), // Continue ticking
-			DoFetchMetadata(d.CurrentStreamURL),
-		)
-
 	case MetadataMsg:
-		// Update track info
-		if msg.Err == nil && msg.Title != "" {
+		// Update track info (late updates from previous station are ignored)
+		if msg.URL == d.CurrentStreamURL {
 			d.Track.SetTrack(msg.Title, msg.Artist)
 		}
-		return d, nil
+		return d, DoWaitMetadata(d.Player.Metadata()) // Wait for the next change
+
+	case PlayerStateMsg:
+		// Show connection state (late events from previous station are ignored)
+		if msg.URL == d.CurrentStreamURL {
+			d.applyPlayerState(msg.StateEvent)
+		}
+		return d, DoWaitState(d.Player.Events()) // Wait for the next change
 
 	case FetchDebounceMsg:
 		// Check debounce validity
@@ -48,139 +57,661 @@ func (d Drums) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return d, nil // Outdated debounce, ignore
 		}
 		// Start station loading (chunk is already playing since key press)
+		// Cache is read first, network is only used for missing or stale entries
 		d.Loading = true
-		return d, DoFetchStations(d.CurrentCountryCode(), d.CurrentGenre())
+		d.FromCache = false
+		d.setOffline(false)
+		return d, DoLoadCachedStations(d.DebounceID, d.query(), d.Filter)
 
-	case FetchStationsMsg:
-		logger.Log.Printf("FetchStationsMsg received: %d stations, err=%v", len(msg.Stations), msg.Err)
-		d.Loading = false
+	case FetchMoreMsg:
+		return d, d.behindSearch(func() tea.Cmd { return d.applyMoreStations(msg) })
+
+	case DirectoryMsg:
 		if msg.Err != nil {
-			// Load error - keep current list
-			logger.Log.Printf("Error loading stations: %v", msg.Err)
+			logger.Log.Printf("Using embedded countries and genres: %v", msg.Err)
 			return d, nil
 		}
-		// Update station list
-		d.Stations = msg.Stations
-		// Update third drum with station names
-		names := make([]string, len(msg.Stations))
-		for i, s := range msg.Stations {
-			names[i] = s.Name
-		}
-		logger.Log.Printf("Updating drum with %d names", len(names))
-		if len(names) > 0 {
-			d.List[2].Items = names
-			d.List[2].Active = 0
-			// Auto-play first station
-			d.CurrentStreamURL = d.Stations[0].Link
-			d.Track.SetTrack(d.Stations[0].Name, "") // Show station name for now
-			return d, tea.Batch(
-				DoPlayStream(d.Player, d.Stations[0].Link),
-				DoFetchMetadata(d.Stations[0].Link), // Request metadata immediately
-			)
+		return d, d.applyDirectory(msg.Directory)
+
+	case StatesMsg:
+		return d, d.applyStates(msg)
+
+	case ResolveStreamMsg:
+		if msg.Err != nil {
+			logger.Log.Printf("Resolve stream: %v", msg.Err)
+			d.Palette.Message = msg.Err.Error()
+			return d, nil
+		}
+		d.tuneTo(msg.Station)
+		return d, DoSwitchStation(d.Player, msg.Station.Link)
+
+	case ShowFavoritesMsg:
+		return d, d.behindSearch(func() tea.Cmd { return d.applyShowFavorites(msg) })
+
+	case CachedStationsMsg:
+		return d, d.behindSearch(func() tea.Cmd { return d.applyCachedStations(msg) })
+
+	case FetchStationsMsg:
+		return d, d.behindSearch(func() tea.Cmd { return d.applyFetchedStations(msg) })
+
+	case CountTimeoutMsg:
+		if msg.ID != d.Count.ID {
+			return d, nil // Count was used or extended
+		}
+		// No motion followed: a lone digit is a preset key
+		return d, d.flushCount(d.takeCount())
+
+	case JumpTimeoutMsg:
+		if msg.ID == d.Jump.ID {
+			d.Jump.Query = "" // Typing paused, next letter starts over
+		}
+		return d, nil
+
+	case SearchDebounceMsg:
+		if msg.ID != d.Search.ID || !d.Search.Shown {
+			return d, nil // Still typing or search closed
+		}
+		return d, d.startSearch()
+
+	case SearchStationsMsg:
+		if !d.Search.Shown || msg.Query != d.Search.Sent {
+			return d, nil // Answer for an older query
+		}
+		d.stopSearch()
+		d.Search.Loading = false
+		d.Search.Err = msg.Err
+		if msg.Err != nil {
+			logger.Log.Printf("Search %q: %v", msg.Query, msg.Err)
+		} else {
+			d.Search.Results = msg.Stations
 		}
+		d.filterSearch()
 		return d, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			// Stop playback on exit
-			if d.Player != nil {
-				d.Player.Cleanup()
-			}
-			player.CleanupChunks()
-			return d, tea.Quit
-
-		// Drum navigation
-		case "up", "k":
-			oldCountry := d.CurrentCountry()
-			oldGenre := d.CurrentGenre()
-			d.List[d.Active].MoveUp()
-			d.ScrollOffset = 0
-			// If in Station column - instant chunk + switch
-			if d.Active == 2 && len(d.Stations) > 0 {
-				idx := d.List[2].Active
-				d.CurrentStreamURL = d.Stations[idx].Link
-				d.Track.SetTrack(d.Stations[idx].Name, "") // Station name for now
-				return d, tea.Batch(
-					DoSwitchStation(d.Player, d.Stations[idx].Link),
-					DoFetchMetadata(d.Stations[idx].Link),
-				)
+		// Overlays take keys first
+		d.Palette.Message = "" // Shown until next key
+		if d.Palette.Active {
+			return d, d.paletteKey(msg)
+		}
+		if d.Search.Active {
+			return d, d.searchKey(msg)
+		}
+		if d.ShowHelp {
+			d.ShowHelp = false // Any key closes help
+			return d, nil
+		}
+		if d.Search.Shown && msg.Type == tea.KeyEsc {
+			d.dismissSearch() // Results kept by Enter
+			return d, nil
+		}
+
+		// Typing continues a jump
+		if cmd, ok := d.jumpKey(msg); ok {
+			return d, cmd
+		}
+
+		// Collect keys of a chord until it resolves
+		seq := append(d.PendingKeys[:len(d.PendingKeys):len(d.PendingKeys)], msg.String())
+		action, more := d.Keys.Resolve(seq)
+		if action == "" && more {
+			d.PendingKeys = seq
+			return d, nil
+		}
+		d.PendingKeys = nil
+		if action == "" && len(seq) > 1 {
+			action, _ = d.Keys.Resolve(seq[len(seq)-1:]) // Broken chord: try last key alone
+		}
+		if len(seq) == 1 {
+			// Unbound and preset digits start a count prefix
+			if cmd, ok := d.countKey(msg, action); ok {
+				return d, cmd
 			}
-			// Instant chunk + debounce on country/genre change
-			return d, d.checkFetchDebounceWithChunk(oldCountry, oldGenre)
-
-		case "down", "j":
-			oldCountry := d.CurrentCountry()
-			oldGenre := d.CurrentGenre()
-			d.List[d.Active].MoveDown()
-			d.ScrollOffset = 0
-			// If in Station column - instant chunk + switch
-			if d.Active == 2 && len(d.Stations) > 0 {
-				idx := d.List[2].Active
-				d.CurrentStreamURL = d.Stations[idx].Link
-				d.Track.SetTrack(d.Stations[idx].Name, "") // Station name for now
-				return d, tea.Batch(
-					DoSwitchStation(d.Player, d.Stations[idx].Link),
-					DoFetchMetadata(d.Stations[idx].Link),
-				)
+		}
+		if action == "" && len(seq) == 1 {
+			// Unbound letters jump within the active drum
+			if cmd, ok := d.startJump(msg); ok {
+				return d, tea.Batch(d.flushCount(d.takeCount()), cmd)
 			}
-			// Instant chunk + debounce on country/genre change
-			return d, d.checkFetchDebounceWithChunk(oldCountry, oldGenre)
+		}
 
-		case "left", "h":
-			d.MoveLeft()
-			d.ScrollOffset = 0
+		return d, d.runCounted(action)
+	}
 
-		case "right", "l":
-			d.MoveRight()
-			d.ScrollOffset = 0
+	return d, nil
+}
 
-		// Volume control
-		case "+", "=":
-			d.Volume.Up()
+// runAction performs action (from keys or command palette)
+func (d *Drums) runAction(action Action) tea.Cmd {
+	// Store mode: the next preset key saves current station
+	if d.StoreMode {
+		d.StoreMode = false
+		if slot, ok := actionSlot(action, "preset-"); ok {
+			d.storePreset(slot)
+		}
+		return nil
+	}
+	if slot, ok := actionSlot(action, "preset-"); ok {
+		return d.recallPreset(slot)
+	}
+	if slot, ok := actionSlot(action, "store-preset-"); ok {
+		d.storePreset(slot)
+		return nil
+	}
 
-		case "-", "_":
-			d.Volume.Down()
+	switch action {
+	case ActionQuit:
+		// Processes and chunks are cleaned up by main after the program exits
+		return tea.Quit
 
-		case "m":
-			d.Volume.ToggleMute()
-		}
+	// Drum navigation
+	case ActionMoveUp:
+		return d.moveBy(-1)
+
+	case ActionMoveDown:
+		return d.moveBy(1)
+
+	case ActionPageUp:
+		return d.moveBy(-d.pageSize())
+
+	case ActionPageDown:
+		return d.moveBy(d.pageSize())
+
+	case ActionHome:
+		return d.moveTo(0)
+
+	case ActionEnd:
+		return d.moveTo(d.ActiveDrum().Len() - 1)
+
+	case ActionPrevColumn:
+		d.MoveLeft()
+		d.ScrollOffset = 0
+
+	case ActionNextColumn:
+		d.MoveRight()
+		d.ScrollOffset = 0
+
+	// Volume control
+	case ActionVolumeUp:
+		d.Volume.Up()
+		d.applyVolume()
+
+	case ActionVolumeDown:
+		d.Volume.Down()
+		d.applyVolume()
+
+	case ActionMute:
+		d.Volume.ToggleMute()
+		d.applyVolume()
+
+	// Station details panel
+	case ActionToggleDetails:
+		d.ShowDetails = !d.ShowDetails
+
+	// Star / unstar selected station
+	case ActionToggleFavorite:
+		d.toggleFavorite()
+
+	// Store mode for presets (then preset key)
+	case ActionStoreMode:
+		d.StoreMode = true
+
+	// Overlays
+	// Sort and quality filters of station lists
+	case ActionCycleSort:
+		return d.cycleSort()
+
+	case ActionCycleCodec:
+		return d.cycleCodec()
+
+	case ActionCycleBitrate:
+		return d.cycleBitrate()
+
+	case ActionToggleHTTPS:
+		d.Filter.HTTPSOnly = !d.Filter.HTTPSOnly
+		return d.applyFilter()
+
+	case ActionClearFilters:
+		d.Filter = provider.Filter{}
+		return d.applyFilter()
+
+	case ActionToggleOrder:
+		return d.toggleOrder()
+
+	case ActionHelp:
+		d.ShowHelp = true
+
+	case ActionCommand:
+		d.Palette.Open()
+
+	case ActionSearch:
+		d.openSearch()
 	}
+	return nil
+}
 
-	return d, nil
+// moveBy scrolls active drum by n items (wrapping around)
+func (d *Drums) moveBy(n int) tea.Cmd {
+	return d.moveTo(d.ActiveDrum().Active + n)
+}
+
+// moveTo selects item of active drum
+// Station column switches station, other columns reload stations
+func (d *Drums) moveTo(index int) tea.Cmd {
+	old := d.selection()
+	if d.isStationColumn() && d.MoreStations {
+		// No wrap-around while the end of the list is unknown
+		index = max(0, min(index, len(d.Stations)-1))
+	}
+	d.ActiveDrum().MoveTo(index)
+	d.ScrollOffset = 0
+	// If in Station column - instant chunk + switch
+	if d.isStationColumn() {
+		if len(d.Stations) == 0 {
+			return nil
+		}
+		st := d.Stations[d.StationDrum().Active]
+		d.tuneTo(st)
+		return tea.Batch(DoSwitchStation(d.Player, st.Link), d.loadMore())
+	}
+	// Instant chunk + debounce on selection change
+	return d.changeColumn(d.Active, old)
 }
 
-// checkFetchDebounce checks if country/genre changed and starts debounce
-func (d *Drums) checkFetchDebounce(oldCountry, oldGenre string) tea.Cmd {
-	newCountry := d.CurrentCountry()
-	newGenre := d.CurrentGenre()
+// changeColumn refills drums right of drum i after its selection changed
+// from old, then reloads stations like checkFetchDebounceWithChunk
+func (d *Drums) changeColumn(i int, old []string) tea.Cmd {
+	clear(d.pending) // Picked by user, restored entries are moot
+	d.fillColumns(i + 1)
+	return d.checkFetchDebounceWithChunk(old)
+}
 
-	// If country or genre changed - start debounce
-	if newCountry != oldCountry || newGenre != oldGenre {
+// checkFetchDebounce checks if selection changed from old and starts debounce
+func (d *Drums) checkFetchDebounce(old []string) tea.Cmd {
+	// If selection changed - start debounce
+	if !slices.Equal(d.selection(), old) {
+		d.closeSearch()
 		d.DebounceID++
-		d.List[2].Items = []string{"Scanning..."}
-		d.List[2].Active = 0
+		d.stopFetch() // Result would be for the old selection
+		d.KeepStream = false
+		d.clearStations("Scanning...")
 		d.Track.SetTrack("", "") // Reset track to scanning state
-		return DoFetchDebounce(d.DebounceID)
+		return tea.Batch(d.loadColumns(), DoFetchDebounce(d.DebounceID))
 	}
 	return nil
 }
 
 // checkFetchDebounceWithChunk same as above + instant chunk on change
-func (d *Drums) checkFetchDebounceWithChunk(oldCountry, oldGenre string) tea.Cmd {
-	newCountry := d.CurrentCountry()
-	newGenre := d.CurrentGenre()
+func (d *Drums) checkFetchDebounceWithChunk(old []string) tea.Cmd {
+	if slices.Equal(d.selection(), old) {
+		return nil
+	}
+	d.closeSearch()
+	d.DebounceID++
+	d.stopFetch() // Result would be for the old selection
+	d.KeepStream = false
+	// Stop current stream and play chunk immediately
+	d.Player.Stop()
+	d.Player.PlayChunkImmediately()
 
-	// If country or genre changed - instant chunk + debounce
-	if newCountry != oldCountry || newGenre != oldGenre {
-		d.DebounceID++
-		d.List[2].Items = []string{"Scanning..."}
-		d.List[2].Active = 0
-		d.Track.SetTrack("", "") // Reset track to scanning state
-		// Stop current stream and play chunk immediately
-		d.Player.Stop()
-		d.Player.PlayChunkImmediately()
-		return DoFetchDebounce(d.DebounceID)
+	// Favorites need no network: fill Station drum right away
+	// (other drums do not apply to favorites)
+	if d.IsFavoritesSource() {
+		return d.showFavorites()
 	}
-	return nil
+
+	d.clearStations("Scanning...")
+	d.Track.SetTrack("", "") // Reset track to scanning state
+	return tea.Batch(d.loadColumns(), DoFetchDebounce(d.DebounceID))
+}
+
+// applyVolume sends current volume level to the player
+func (d *Drums) applyVolume() {
+	d.Player.SetVolume(d.Volume.Effective())
+}
+
+// applyPlayerState shows playback state of current stream in track area
+func (d *Drums) applyPlayerState(ev player.StateEvent) {
+	station := d.CurrentStreamName
+	switch ev.State {
+	case player.StateConnecting:
+		d.Track.SetStatus("CONNECTING...", station)
+	case player.StateBuffering:
+		d.Track.SetStatus("BUFFERING...", station)
+	case player.StateReconnecting:
+		d.Track.SetTrack("", "")
+		d.Track.SetStatus(fmt.Sprintf("RECONNECTING (%d/%d)...", ev.Attempt, player.MaxReconnects), errorText(ev.Err))
+	case player.StateFailed:
+		d.Track.SetTrack("", "")
+		d.Track.SetStatus("FAILED", errorText(ev.Err))
+		logger.Log.Printf("Stream %s failed: %v", ev.URL, ev.Err)
+	default:
+		d.Track.SetStatus("", "")
+	}
+}
+
+// errorText returns error message or empty string for nil
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// applyMoreStations appends the next page of stations
+func (d *Drums) applyMoreStations(msg FetchMoreMsg) tea.Cmd {
+	if msg.ID != d.DebounceID || !d.LoadingMore {
+		return nil // Answer for previous selection, ignore
+	}
+	d.stopFetch()
+	d.LoadingMore = false
+	if msg.Err != nil {
+		// Keep what is loaded, the next scroll near the end retries
+		logger.Log.Printf("Error loading more stations: %v", msg.Err)
+		return nil
+	}
+	d.Pages++
+	d.MoreStations = msg.More // Pages advance, so an empty one cannot repeat
+	d.Stations = msg.Stations
+	d.StationDrum().Items = d.stationItems() // Indices are kept, selection stays
+	d.updateStationTitle()
+	return d.loadMore()
+}
+
+// applyShowFavorites shows favorites unless the selection changed meanwhile
+func (d *Drums) applyShowFavorites(msg ShowFavoritesMsg) tea.Cmd {
+	if msg.ID != d.DebounceID || !d.IsFavoritesSource() {
+		return nil // Selection changed meanwhile
+	}
+	return d.showFavorites()
+}
+
+// applyCachedStations shows cached stations, fetching missing or stale ones
+func (d *Drums) applyCachedStations(msg CachedStationsMsg) tea.Cmd {
+	if msg.ID != d.DebounceID {
+		return nil // Selection changed meanwhile
+	}
+	if len(msg.Stations) == 0 {
+		logger.Log.Printf("No cached stations for %s", msg.Query.Key())
+		return d.startFetch(msg.Query)
+	}
+	logger.Log.Printf("Cached stations for %s: %d (fresh=%v)", msg.Query.Key(), len(msg.Stations), msg.Fresh)
+	d.MoreStations = len(msg.Stations) >= provider.StationLimit // Cache keeps whole pages
+	d.Pages = msg.Pages
+	if d.Pages == 0 {
+		d.Pages = max(1, len(msg.Stations)/provider.StationLimit) // Saved by an older version
+	}
+	cmd := d.showStations(msg.Stations)
+	if msg.Fresh {
+		d.Loading = false
+		return cmd
+	}
+	// Stale: keep playing from cache and revalidate in background
+	d.FromCache = true
+	return tea.Batch(cmd, d.startFetch(msg.Query))
+}
+
+// applyFetchedStations shows fetched stations (or refreshes cached ones)
+func (d *Drums) applyFetchedStations(msg FetchStationsMsg) tea.Cmd {
+	logger.Log.Printf("FetchStationsMsg received: %d stations, err=%v", len(msg.Stations), msg.Err)
+	if msg.ID != d.DebounceID {
+		return nil // Answer for previous selection, ignore
+	}
+	d.stopFetch() // Done, release request context
+	d.Loading = false
+	if msg.Err != nil {
+		// Load error - keep current list (offline if it came from cache)
+		logger.Log.Printf("Error loading stations: %v", msg.Err)
+		d.setOffline(d.FromCache)
+		return nil
+	}
+	d.setOffline(false)
+	if len(msg.Stations) == 0 {
+		d.markEmpty()
+	}
+	d.MoreStations = msg.More
+	d.Pages = 1
+	if d.FromCache {
+		// Background refresh: update list without interrupting playback
+		d.FromCache = false
+		d.refreshStations(msg.Stations)
+		return nil
+	}
+	return d.showStations(msg.Stations)
+}
+
+// showStations fills Station drum and auto-plays the first station
+func (d *Drums) showStations(stations []data.Station) tea.Cmd {
+	// Update station list
+	d.Stations = stations
+	// Update third drum with station names
+	names := make([]string, len(stations))
+	for i, s := range stations {
+		names[i] = d.stationLabel(s)
+	}
+	logger.Log.Printf("Updating drum with %d names", len(names))
+	if len(names) == 0 {
+		d.clearStations("No stations")
+		return nil
+	}
+	if d.KeepStream {
+		// Stream was resumed on start: show list, keep it playing
+		d.KeepStream = false
+		d.refreshStations(stations)
+		return nil
+	}
+	d.StationDrum().Items = d.stationItems()
+	d.StationDrum().Active = 0
+	d.updateStationTitle()
+	// Auto-play first station
+	d.tuneTo(d.Stations[0])
+	return DoPlayStream(d.Player, d.Stations[0].Link)
+}
+
+// refreshStations replaces shown stations with refreshed ones
+// The playing station stays selected if it is still in the list
+func (d *Drums) refreshStations(stations []data.Station) {
+	if len(stations) == 0 {
+		return
+	}
+	d.Stations = stations
+	active := 0
+	for i, s := range stations {
+		if s.Link == d.CurrentStreamURL {
+			active = i
+		}
+	}
+	d.StationDrum().Items = d.stationItems()
+	d.StationDrum().Active = active
+	d.updateStationTitle()
+}
+
+// stationItems returns Station drum items for loaded stations
+// A placeholder marks the end while more pages may exist, so the drum
+// does not seem to wrap around to the first station
+func (d *Drums) stationItems() []Item {
+	items := make([]Item, 0, len(d.Stations)+1)
+	for _, s := range d.Stations {
+		items = append(items, Item{Name: d.stationLabel(s)})
+	}
+	if d.MoreStations {
+		items = append(items, Item{Name: "Loading more..."})
+	}
+	return items
+}
+
+// clearStations empties Station drum showing text instead
+func (d *Drums) clearStations(text string) {
+	d.Stations = nil
+	d.MoreStations = false
+	d.Pages = 0
+	d.LoadingMore = false
+	d.StationDrum().Items = textItems(text)
+	d.StationDrum().Active = 0
+	d.updateStationTitle()
+}
+
+// updateStationTitle shows number of stations and offline mode in Station drum title
+func (d *Drums) updateStationTitle() {
+	var notes []string
+	if n := len(d.Stations); n > 0 {
+		count := strconv.Itoa(n)
+		if d.MoreStations {
+			count += "+"
+		}
+		notes = append(notes, count)
+	}
+	if f := d.Filter.String(); f != "" && !d.IsFavoritesSource() {
+		notes = append(notes, f)
+	}
+	if d.Offline {
+		notes = append(notes, "offline")
+	}
+	d.StationDrum().Title = "Station"
+	if len(notes) > 0 {
+		d.StationDrum().Title += " (" + strings.Join(notes, ", ") + ")"
+	}
+}
+
+// loadMore fetches the next page of stations when selection nears the end
+func (d *Drums) loadMore() tea.Cmd {
+	if !d.MoreStations || d.LoadingMore || d.Loading || d.Search.Shown || d.IsFavoritesSource() {
+		return nil
+	}
+	if len(d.Stations)-d.StationDrum().Active > d.pageSize() {
+		return nil
+	}
+	d.LoadingMore = true
+	d.stopFetch()
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancelFetch = cancel
+	return DoFetchMoreStations(ctx, d.DebounceID, d.query(), d.Filter, d.Stations, d.Pages)
+}
+
+// setOffline marks Station drum as showing cached stations only
+func (d *Drums) setOffline(offline bool) {
+	d.Offline = offline
+	d.updateStationTitle()
+}
+
+// startFetch cancels station request in flight and starts a new one
+func (d *Drums) startFetch(q provider.StationQuery) tea.Cmd {
+	d.stopFetch()
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancelFetch = cancel
+	return DoFetchStations(ctx, d.DebounceID, q, d.Filter)
+}
+
+// stopFetch cancels station request in flight (if any)
+func (d *Drums) stopFetch() {
+	if d.cancelFetch != nil {
+		d.cancelFetch()
+		d.cancelFetch = nil
+	}
+}
+
+// showFavorites fills Station drum with favorites and plays the first one
+func (d *Drums) showFavorites() tea.Cmd {
+	d.Loading = false
+	d.FromCache = false
+	d.setOffline(false)
+
+	stations := make([]data.Station, len(d.Favorites))
+	for i, f := range d.Favorites {
+		stations[i] = f.Station()
+	}
+	d.MoreStations = false
+	if len(stations) == 0 {
+		d.clearStations("No favorites (press f)")
+		d.Track.SetTrack("", "")
+		return nil
+	}
+	return d.showStations(stations)
+}
+
+// favoriteIndex returns index of favorite with stream url or -1
+func (d *Drums) favoriteIndex(url string) int {
+	for i, f := range d.Favorites {
+		if f.URL == url {
+			return i
+		}
+	}
+	return -1
+}
+
+// stationLabel returns station name for the drum (starred if favorite)
+func (d *Drums) stationLabel(s data.Station) string {
+	if d.favoriteIndex(s.Link) >= 0 {
+		return "★ " + s.Name
+	}
+	return s.Name
+}
+
+// stationOrigin returns country code and genre selected station was found under
+func (d *Drums) stationOrigin(st data.Station) (string, string) {
+	if !d.IsFavoritesSource() && !d.Search.Shown {
+		return d.CurrentCountryCode(), d.CurrentGenre()
+	}
+	// Favorites and search results keep their own country/genre
+	genre := ""
+	if len(st.Tags) > 0 {
+		genre = st.Tags[0]
+	}
+	return st.CountryCode, genre
+}
+
+// toggleFavorite stars or unstars selected station and saves favorites
+func (d *Drums) toggleFavorite() {
+	st, ok := d.CurrentStation()
+	if !ok {
+		return
+	}
+	if i := d.favoriteIndex(st.Link); i >= 0 {
+		d.Favorites = append(d.Favorites[:i:i], d.Favorites[i+1:]...)
+	} else {
+		country, genre := d.stationOrigin(st)
+		d.Favorites = append(d.Favorites[:len(d.Favorites):len(d.Favorites)], store.NewFavorite(st, country, genre))
+	}
+	drum := d.StationDrum()
+	drum.Items[drum.Active].Name = d.stationLabel(st)
+
+	if err := store.SaveFavorites(d.Favorites); err != nil {
+		logger.Log.Printf("Save favorites: %v", err)
+	}
+}
+
+// tuneTo makes station the current stream (playback is started by caller)
+func (d *Drums) tuneTo(st data.Station) {
+	d.CurrentStreamURL = st.Link
+	d.CurrentStreamName = st.Name
+	d.Track.SetTrack(st.Name, "") // Station name until metadata arrives
+}
+
+// storePreset saves current stream into slot and persists presets
+func (d *Drums) storePreset(slot int) {
+	if d.CurrentStreamURL == "" {
+		return
+	}
+	// Details are known only if the stream is the station selected in the drum
+	st := data.Station{Name: d.CurrentStreamName, Link: d.CurrentStreamURL}
+	country, genre := "", ""
+	if cur, ok := d.CurrentStation(); ok && cur.Link == st.Link {
+		st = cur
+		country, genre = d.stationOrigin(st)
+	}
+	d.Presets[slot] = store.NewFavorite(st, country, genre)
+
+	if err := store.SavePresets(d.Presets); err != nil {
+		logger.Log.Printf("Save presets: %v", err)
+	}
+}
+
+// recallPreset tunes to station in slot with the usual chunk transition
+func (d *Drums) recallPreset(slot int) tea.Cmd {
+	preset := d.Presets[slot]
+	if preset.URL == "" || preset.URL == d.CurrentStreamURL {
+		return nil
+	}
+	d.tuneTo(preset.Station())
+	return DoSwitchStation(d.Player, preset.URL)
 }
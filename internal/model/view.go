@@ -1,6 +1,8 @@
 package model
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -27,10 +29,20 @@ func (d Drums) View() string {
 		columns = append(columns, column)
 	}
 	drums := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	if d.ShowHelp {
+		drums = d.renderHelp() // Overlay takes place of drums
+	}
 
-	return header + "\n\n" + drums
+	view := header + "\n\n" + drums
+	if d.ShowDetails && !d.ShowHelp {
+		view += "\n" + d.renderDetails()
+	}
+	return view + "\n" + d.renderBottomLine()
 }
 
+// VolumeBarWidth is the width of volume bar in header (in characters)
+const VolumeBarWidth = 10
+
 // renderHeader renders the header panel (track on left, clock on right)
 func (d *Drums) renderHeader() string {
 	// Get clock display
@@ -54,8 +66,11 @@ func (d *Drums) renderHeader() string {
 	artist = ui.Truncate(artist, trackWidth-2)
 	name = ui.Truncate(name, trackWidth-2)
 
+	// Volume bar
+	volume := d.Volume.DisplayBar(VolumeBarWidth)
+
 	// Build lines: track on left, clock on right
-	// Clock is 5 lines, track on lines 2 and 3 (with top padding)
+	// Clock is 5 lines, track on lines 2 and 3 (with top padding), volume on line 4
 	var lines []string
 	for i, clockLine := range clockLines {
 		trackPart := ""
@@ -63,10 +78,14 @@ func (d *Drums) renderHeader() string {
 			trackPart = "  " + artist
 		} else if i == 2 {
 			trackPart = "  " + name
+		} else if i == 3 {
+			trackPart = "  " + volume
+		} else if i == 4 {
+			trackPart = "  " + d.renderPresets()
 		}
 
-		// Padding between track and clock
-		trackPartWidth := runewidth.StringWidth(trackPart)
+		// Padding between track and clock (parts may contain styles)
+		trackPartWidth := lipgloss.Width(trackPart)
 		padding := d.Width - trackPartWidth - runewidth.StringWidth(clockLine)
 		if padding < 0 {
 			padding = 0
@@ -78,6 +97,32 @@ func (d *Drums) renderHeader() string {
 	return strings.Join(lines, "\n")
 }
 
+// renderPresets renders preset strip: stored slots are bright,
+// the slot of the playing station is highlighted, free slots are dim
+func (d *Drums) renderPresets() string {
+	free := lipgloss.NewStyle().Foreground(ui.InactiveColor)
+	stored := lipgloss.NewStyle().Bold(true)
+	tuned := lipgloss.NewStyle().Foreground(ui.ActiveColor).Bold(true)
+
+	var slots []string
+	for i, p := range d.Presets {
+		n := strconv.Itoa(i + 1)
+		switch {
+		case p.URL == "":
+			slots = append(slots, free.Render(" "+n+" "))
+		case p.URL == d.CurrentStreamURL:
+			slots = append(slots, tuned.Render("["+n+"]"))
+		default:
+			slots = append(slots, stored.Render(" "+n+" "))
+		}
+	}
+	strip := strings.Join(slots, "")
+	if d.StoreMode {
+		strip = tuned.Render("STORE ") + strip
+	}
+	return strip
+}
+
 // renderDrum renders a single drum column
 func (d *Drums) renderDrum(drum *Drum, isActiveColumn bool) string {
 	var items []string
@@ -105,7 +150,7 @@ func (d *Drums) renderDrum(drum *Drum, isActiveColumn bool) string {
 	// Display only VisibleItems items with active in center
 	for j := 0; j < ui.VisibleItems; j++ {
 		offset := j - middle
-		item := drum.GetItem(drum.Active + offset)
+		item := drum.Label(drum.Active + offset)
 
 		isCenter := j == middle
 
@@ -134,6 +179,137 @@ func (d *Drums) renderDrum(drum *Drum, isActiveColumn bool) string {
 	return ui.RenderBoxWithTitle(content, drum.Title, d.ColumnWidth(), borderColor, borderColor)
 }
 
+// renderBottomLine renders palette input, last command result or key help
+func (d *Drums) renderBottomLine() string {
+	dim := lipgloss.NewStyle().Foreground(ui.InactiveColor)
+	switch {
+	case d.Search.Active:
+		status := fmt.Sprintf("%d found", len(d.Search.Results))
+		switch {
+		case d.Search.Loading:
+			status = "searching..."
+		case d.Search.Err != nil:
+			status = d.Search.Err.Error()
+		}
+		input := ui.Truncate("/"+d.Search.Query+"█", d.Width)
+		hint := ui.Truncate(" "+status+" · tag:NAME lang:NAME · ↑↓ select · enter play · esc cancel", d.Width)
+		return input + "\n" + dim.Render(hint)
+	case d.Palette.Active:
+		input := ui.Truncate(":"+d.Palette.Input+"█", d.Width)
+		matches := ui.Truncate(" "+strings.Join(d.Palette.Matches, "  "), d.Width)
+		return input + "\n" + dim.Render(matches)
+	case d.Jump.Query != "":
+		return dim.Render(" jump: ") + d.Jump.Query + "█"
+	case len(d.Count.Keys) > 0:
+		return dim.Render(" count: ") + strings.Join(d.Count.Keys, "")
+	case d.Palette.Message != "":
+		return lipgloss.NewStyle().Foreground(ui.ActiveColor).Render(ui.Truncate(" "+d.Palette.Message, d.Width))
+	default:
+		return d.renderHelpLine()
+	}
+}
+
+// renderHelp renders help overlay: every action with its keys, then palette commands
+func (d *Drums) renderHelp() string {
+	width := d.ColumnWidth() * len(d.List)
+
+	rows := [][2]string{}
+	for _, e := range d.Keys.Help() {
+		rows = append(rows, [2]string{e.Keys, e.Help})
+	}
+	rows = append(rows, [2]string{"", ""})
+	for _, c := range commands() {
+		if c.usage == "" {
+			continue // Actions are listed above with their keys
+		}
+		rows = append(rows, [2]string{":" + c.name + " " + c.usage, c.help})
+	}
+	rows = append(rows, [2]string{":ACTION", "Run any action by name (e.g. :mute, :quit)"})
+
+	// Description column starts after the longest keys
+	keysWidth := 0
+	for _, r := range rows {
+		keysWidth = max(keysWidth, runewidth.StringWidth(r[0]))
+	}
+
+	var lines []string
+	for _, r := range rows {
+		line := " " + runewidth.FillRight(r[0], keysWidth) + "  " + r[1]
+		lines = append(lines, ui.Truncate(line, width-3))
+	}
+	return ui.RenderBoxWithTitle(strings.Join(lines, "\n"), "Help (any key closes)", width, ui.InactiveColor, ui.ActiveColor)
+}
+
+// renderHelpLine renders key help generated from the keymap
+func (d *Drums) renderHelpLine() string {
+	var parts []string
+	for _, e := range d.Keys.Help() {
+		parts = append(parts, e.Keys+" "+strings.ToLower(e.Help))
+	}
+	line := ui.Truncate(" "+strings.Join(parts, " · "), d.Width)
+	return lipgloss.NewStyle().Foreground(ui.InactiveColor).Render(line)
+}
+
+// renderDetails renders directory details of selected station
+func (d *Drums) renderDetails() string {
+	width := d.ColumnWidth() * len(d.List)
+	st, ok := d.CurrentStation()
+	if !ok {
+		return ui.RenderBoxWithTitle(" No station selected", "Details", width, ui.InactiveColor, ui.InactiveColor)
+	}
+
+	check := ""
+	if !st.LastCheck.IsZero() {
+		status := "OK"
+		if !st.LastCheckOK {
+			status = "FAILED"
+		}
+		check = status + " at " + st.LastCheck.Local().Format("2006-01-02 15:04")
+	}
+	geo := ""
+	if st.HasGeo() {
+		geo = fmt.Sprintf("%.4f, %.4f", st.GeoLat, st.GeoLong)
+	}
+	popularity := ""
+	if st.Votes > 0 || st.Clicks > 0 {
+		popularity = fmt.Sprintf("%d votes, %d clicks", st.Votes, st.Clicks)
+	}
+
+	fields := [][2]string{
+		{"Name", st.Name},
+		{"Stream", st.Link},
+		{"Homepage", st.Homepage},
+		{"Favicon", st.Favicon},
+		{"Tags", strings.Join(st.Tags, ", ")},
+		{"Language", st.Language},
+		{"Quality", st.Quality()},
+		{"Location", st.Location()},
+		{"Geo", geo},
+		{"Popularity", popularity},
+		{"Last check", check},
+		{"Listed song", st.Song},
+		{"UUID", st.UUID},
+		{"Source", st.Source},
+	}
+
+	// Value column starts after the longest label
+	labelWidth := 0
+	for _, f := range fields {
+		labelWidth = max(labelWidth, runewidth.StringWidth(f[0]))
+	}
+
+	var lines []string
+	for _, f := range fields {
+		value := f[1]
+		if value == "" {
+			value = "-"
+		}
+		line := " " + runewidth.FillRight(f[0], labelWidth) + "  " + value
+		lines = append(lines, ui.Truncate(line, width-3))
+	}
+	return ui.RenderBoxWithTitle(strings.Join(lines, "\n"), "Details", width, ui.InactiveColor, ui.ActiveColor)
+}
+
 // centerText centers text within given width accounting for character widths
 func centerText(s string, width int) string {
 	textWidth := runewidth.StringWidth(s)
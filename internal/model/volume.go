@@ -48,6 +48,14 @@ func (v *Volume) SetLevel(level int) {
 	v.Level = level
 }
 
+// Effective returns level that should reach the speakers (0 when muted)
+func (v *Volume) Effective() int {
+	if v.Muted {
+		return 0
+	}
+	return v.Level
+}
+
 // DisplayBar returns visual volume bar representation
 // width is the bar width in characters
 func (v *Volume) DisplayBar(width int) string {
@@ -0,0 +1,138 @@
+package player
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"crr/internal/audio"
+)
+
+// State describes what a backend is currently doing
+type State int
+
+// Playback states
+const (
+	StateStopped      State = iota // Nothing is playing
+	StatePlaying                   // Stream is playing
+	StatePaused                    // Stream is paused
+	StateConnecting                // Connecting to stream
+	StateBuffering                 // Connected, waiting for audio
+	StateReconnecting              // Stream dropped, retrying
+	StateFailed                    // Stream cannot be played
+)
+
+// String returns state name for display
+func (s State) String() string {
+	switch s {
+	case StatePlaying:
+		return "Playing"
+	case StatePaused:
+		return "Paused"
+	case StateConnecting:
+		return "Connecting"
+	case StateBuffering:
+		return "Buffering"
+	case StateReconnecting:
+		return "Reconnecting"
+	case StateFailed:
+		return "Failed"
+	default:
+		return "Stopped"
+	}
+}
+
+// Backend is an audio engine driven by Player
+type Backend interface {
+	// Play starts stream playback, replacing current playback
+	Play(url string) error
+	// PlayWithIntro plays chunk file and crossfades into the stream
+	PlayWithIntro(chunk, url string) error
+	// PlayEffect plays a short file on top of current playback
+	PlayEffect(path string) error
+	// Stop stops current playback
+	Stop() error
+	// SetVolume sets stream volume level (0-100)
+	SetVolume(level int) error
+	// SetPaused pauses or resumes current stream
+	SetPaused(paused bool) error
+	// State reports current playback state
+	State() State
+	// Metadata returns channel receiving a TrackInfo on every track change
+	Metadata() <-chan TrackInfo
+	// Events returns channel receiving stream state changes
+	// A stream that ends or breaks is reported as StateFailed
+	Events() <-chan StateEvent
+	// Close stops playback and releases all resources
+	Close() error
+}
+
+// BackendFactory creates a new backend instance
+type BackendFactory func() Backend
+
+var (
+	backends   = map[string]BackendFactory{}
+	backendsMu sync.Mutex
+)
+
+// RegisterBackend makes a backend available under name
+func RegisterBackend(name string, factory BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[strings.ToLower(name)] = factory
+}
+
+// NewBackend creates the backend registered under name
+// Empty name or "auto" selects backend based on installed programs
+func NewBackend(name string) (Backend, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "auto" {
+		name = DetectBackend()
+	}
+
+	backendsMu.Lock()
+	factory, ok := backends[name]
+	backendsMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown audio backend %q (available: %s)",
+			name, strings.Join(BackendNames(), ", "))
+	}
+	return factory(), nil
+}
+
+// BackendNames returns sorted names of registered backends
+func BackendNames() []string {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectBackend returns name of the best backend available on this machine
+// mpv is preferred for gapless switching, then ffplay, then the in-process
+// native pipeline if a PCM sink exists, then silent null
+func DetectBackend() string {
+	if _, err := exec.LookPath("mpv"); err == nil {
+		return "mpv"
+	}
+	if _, err := exec.LookPath("ffplay"); err == nil {
+		return "ffplay"
+	}
+	if SinkSpec != "" || audio.DetectSink() != "" {
+		return "native"
+	}
+	return "null"
+}
+
+func init() {
+	RegisterBackend("ffplay", func() Backend { return NewFFplay() })
+	RegisterBackend("mpv", func() Backend { return NewMPV() })
+	RegisterBackend("native", func() Backend { return NewNative() })
+	RegisterBackend("null", func() Backend { return NewNull() })
+}
@@ -0,0 +1,47 @@
+package player
+
+import "errors"
+
+// errStreamEnded is reported when a live stream closes by itself
+var errStreamEnded = errors.New("stream ended")
+
+// StateEvent reports playback state of a stream
+type StateEvent struct {
+	URL     string // Stream the event belongs to
+	State   State  // New state
+	Err     error  // Cause of StateFailed or StateReconnecting
+	Attempt int    // Reconnect attempt number (StateReconnecting)
+}
+
+// stateFeed delivers state changes, dropping the oldest if nobody reads
+type stateFeed chan StateEvent
+
+// newStateFeed creates an empty feed
+func newStateFeed() stateFeed {
+	return make(stateFeed, 16)
+}
+
+// send delivers event
+func (f stateFeed) send(ev StateEvent) {
+	sendLatest(f, ev)
+}
+
+// report delivers state of url
+func (f stateFeed) report(url string, state State, err error) {
+	f.send(StateEvent{URL: url, State: state, Err: err})
+}
+
+// sendLatest sends v to ch, dropping oldest value if ch is full
+func sendLatest[T any](ch chan T, v T) {
+	for {
+		select {
+		case ch <- v:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package player
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"crr/internal/audio"
+	"crr/internal/proc"
+)
+
+// PCM format passed from ffmpeg through the gain stage to ffplay
+const (
+	pcmSampleRate = 44100
+	pcmChannels   = 2
+)
+
+// FFplay is a backend that plays audio through ffplay processes
+// The stream connection is owned by Go (ICY metadata is read from it),
+// ffmpeg decodes (and crossfades chunk → stream), the PCM passes through
+// an in-process gain stage, so volume changes apply without reconnecting
+type FFplay struct {
+	ffmpeg *exec.Cmd          // ffmpeg process (decode, crossfade)
+	ffplay *exec.Cmd          // ffplay process (playback)
+	stream io.Closer          // stream connection fed to ffmpeg
+	cancel context.CancelFunc // cancels stream connection
+	feed   metadataFeed       // ICY titles of current stream
+	events stateFeed          // state changes of current stream
+	gen    int                // pipeline generation (changes on every start/stop)
+	url    string             // current stream URL (for resume)
+	volume int                // stream volume level (0-100)
+	gain   atomic.Uint64      // linear gain bits, read by the copy goroutine
+	paused bool               // whether stream is paused
+	mu     sync.Mutex         // race condition protection
+}
+
+// NewFFplay creates a new ffplay backend
+func NewFFplay() *FFplay {
+	f := &FFplay{feed: newMetadataFeed(), events: newStateFeed()}
+	f.setGain(100)
+	return f
+}
+
+// Metadata returns channel with ICY title changes
+func (f *FFplay) Metadata() <-chan TrackInfo {
+	return f.feed
+}
+
+// Events returns channel with stream state changes
+func (f *FFplay) Events() <-chan StateEvent {
+	return f.events
+}
+
+// openLocked connects to stream, reading ICY titles (call with mutex held)
+func (f *FFplay) openLocked(url string) (io.Reader, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := audio.Open(ctx, url, func(title string) { f.feed.push(url, title) })
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	f.stream = stream
+	f.cancel = cancel
+	return stream, nil
+}
+
+// setGain stores gain for level
+func (f *FFplay) setGain(level int) {
+	f.volume = clampLevel(level)
+	f.gain.Store(math.Float64bits(float64(f.volume) / 100))
+}
+
+// Play plays stream directly without crossfade
+func (f *FFplay) Play(url string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stopLocked()
+	f.url = url
+	f.paused = false
+	return f.playDirectLocked(url)
+}
+
+// playDirectLocked starts pipeline on stream URL (call with mutex held)
+func (f *FFplay) playDirectLocked(url string) error {
+	stream, err := f.openLocked(url)
+	if err != nil {
+		return err
+	}
+	return f.startLocked(stream,
+		"-i", "pipe:0",
+		"-af", "volume="+StreamVolumeDB+"dB",
+	)
+}
+
+// PlayWithIntro plays chunk first, then crossfades into stream
+// stream | ffmpeg -i chunk.mp3 -i pipe:0 -filter_complex "acrossfade" -f s16le - | gain | ffplay -
+func (f *FFplay) PlayWithIntro(chunk, url string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.stopLocked()
+	f.url = url
+	f.paused = false
+
+	stream, err := f.openLocked(url)
+	if err != nil {
+		return err
+	}
+	return f.startLocked(stream,
+		"-i", chunk, // chunk as first input
+		"-i", "pipe:0", // stream as second input
+		"-filter_complex",
+		"[0:a]apad=pad_dur=1.2[a0];[a0][1:a]acrossfade=d=1:c1=tri:c2=tri,volume="+StreamVolumeDB+"dB[out]",
+		"-map", "[out]",
+	)
+}
+
+// startLocked starts stream → ffmpeg → gain stage → ffplay (call with mutex held)
+func (f *FFplay) startLocked(stream io.Reader, inputs ...string) error {
+	args := append([]string{"-loglevel", "error"}, inputs...)
+	args = append(args,
+		"-f", "s16le",
+		"-ar", strconv.Itoa(pcmSampleRate),
+		"-ac", strconv.Itoa(pcmChannels),
+		"-",
+	)
+	stderr := &lastLineWriter{}
+	f.ffmpeg = exec.Command("ffmpeg", args...)
+	f.ffmpeg.Stdin = stream
+	f.ffmpeg.Stderr = stderr
+
+	// ffplay: playback from pipe (WAV header is written by the gain stage)
+	f.ffplay = exec.Command("ffplay",
+		"-nodisp",
+		"-loglevel", "quiet",
+		"-i", "-",
+	)
+
+	src, err := f.ffmpeg.StdoutPipe()
+	if err != nil {
+		f.stopLocked()
+		return err
+	}
+	dst, err := f.ffplay.StdinPipe()
+	if err != nil {
+		f.stopLocked()
+		return err
+	}
+
+	// Start both processes
+	if err := proc.Start(f.ffmpeg); err != nil {
+		f.ffmpeg = nil
+		f.stopLocked()
+		return err
+	}
+	if err := proc.Start(f.ffplay); err != nil {
+		ffmpeg := f.ffmpeg
+		f.ffplay = nil
+		f.stopLocked()
+		go proc.Wait(ffmpeg) // Reap killed ffmpeg
+		return err
+	}
+
+	f.events.report(f.url, StateBuffering, nil)
+	go f.supervise(f.gen, f.url, f.ffmpeg, f.ffplay, dst, src, stderr)
+	return nil
+}
+
+// supervise runs the gain stage and reports when the pipeline breaks
+func (f *FFplay) supervise(gen int, url string, ffmpeg, ffplay *exec.Cmd, dst io.WriteCloser, src io.Reader, stderr *lastLineWriter) {
+	f.copyWithGain(dst, src, func() { f.reportIfCurrent(gen, url, StatePlaying, nil) })
+
+	// Either side ended, the pipeline cannot continue
+	proc.Kill(ffmpeg)
+	proc.Kill(ffplay)
+	proc.Wait(ffmpeg)
+	proc.Wait(ffplay)
+
+	f.mu.Lock()
+	current := gen == f.gen
+	if current {
+		// Reaped above: their pids may belong to other processes by now
+		if f.ffmpeg == ffmpeg {
+			f.ffmpeg = nil
+		}
+		if f.ffplay == ffplay {
+			f.ffplay = nil
+		}
+		f.stopLocked()
+	}
+	f.mu.Unlock()
+
+	if current {
+		err := errStreamEnded
+		if line := stderr.String(); line != "" {
+			err = errors.New(line)
+		}
+		f.events.report(url, StateFailed, err)
+	}
+}
+
+// reportIfCurrent reports state if pipeline gen is still playing
+func (f *FFplay) reportIfCurrent(gen int, url string, state State, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if gen == f.gen {
+		f.events.report(url, state, err)
+	}
+}
+
+// copyWithGain copies PCM from ffmpeg to ffplay applying current gain
+// onStart is called when the first audio passes through
+func (f *FFplay) copyWithGain(dst io.WriteCloser, src io.Reader, onStart func()) {
+	defer dst.Close()
+
+	if _, err := dst.Write(wavHeader()); err != nil {
+		return
+	}
+
+	buf := make([]byte, 4096)
+	carry := 0 // odd byte left from previous read
+	for {
+		n, err := src.Read(buf[carry:])
+		n += carry
+		whole := n &^ 1
+
+		gain := math.Float64frombits(f.gain.Load())
+		for i := 0; i < whole; i += 2 {
+			v := float64(int16(binary.LittleEndian.Uint16(buf[i:]))) * gain
+			binary.LittleEndian.PutUint16(buf[i:], uint16(int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, v)))))
+		}
+		if _, werr := dst.Write(buf[:whole]); werr != nil {
+			return
+		}
+		if whole > 0 && onStart != nil {
+			onStart()
+			onStart = nil
+		}
+		carry = copy(buf, buf[whole:n])
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// wavHeader returns header of an endless 16-bit PCM WAV stream
+func wavHeader() []byte {
+	const unknownSize = 0xFFFFFFFF
+	h := make([]byte, 0, 44)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, unknownSize)
+	h = append(h, "WAVEfmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)                          // fmt chunk size
+	h = binary.LittleEndian.AppendUint16(h, 1)                           // PCM
+	h = binary.LittleEndian.AppendUint16(h, pcmChannels)                 // channels
+	h = binary.LittleEndian.AppendUint32(h, pcmSampleRate)               // sample rate
+	h = binary.LittleEndian.AppendUint32(h, pcmSampleRate*pcmChannels*2) // byte rate
+	h = binary.LittleEndian.AppendUint16(h, pcmChannels*2)               // block align
+	h = binary.LittleEndian.AppendUint16(h, 16)                          // bits per sample
+	h = append(h, "data"...)
+	h = binary.LittleEndian.AppendUint32(h, unknownSize)
+	return h
+}
+
+// PlayEffect plays a file in a separate process (louder than stream)
+// Loudness follows current volume level
+func (f *FFplay) PlayEffect(path string) error {
+	gain := math.Float64frombits(f.gain.Load())
+	cmd := exec.Command("ffplay",
+		"-nodisp", "-autoexit", "-loglevel", "quiet",
+		"-af", fmt.Sprintf("volume=%sdB,volume=%.2f", ChunkVolumeDB, gain),
+		path,
+	)
+	return proc.Spawn(cmd)
+}
+
+// Stop stops current playback
+func (f *FFplay) Stop() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.url = ""
+	f.paused = false
+	return f.stopLocked()
+}
+
+// stopLocked kills running processes and closes stream (call with mutex held)
+func (f *FFplay) stopLocked() error {
+	f.gen++
+	if f.cancel != nil {
+		f.cancel()
+		f.cancel = nil
+	}
+	if f.stream != nil {
+		f.stream.Close()
+		f.stream = nil
+	}
+	proc.Kill(f.ffplay)
+	proc.Kill(f.ffmpeg)
+	f.ffplay, f.ffmpeg = nil, nil
+	return nil
+}
+
+// SetVolume changes volume of running stream
+func (f *FFplay) SetVolume(level int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setGain(level)
+	return nil
+}
+
+// SetPaused pauses or resumes stream
+// Live streams cannot be buffered while paused, so pause disconnects
+// and resume reconnects to the same URL
+func (f *FFplay) SetPaused(paused bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if paused == f.paused || f.url == "" {
+		return nil
+	}
+	f.paused = paused
+	if paused {
+		return f.stopLocked()
+	}
+	return f.playDirectLocked(f.url)
+}
+
+// State reports current playback state
+func (f *FFplay) State() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case f.paused:
+		return StatePaused
+	case f.ffplay != nil && f.ffplay.Process != nil:
+		return StatePlaying
+	default:
+		return StateStopped
+	}
+}
+
+// Close stops playback pipeline (effects are killed by proc.KillAll)
+func (f *FFplay) Close() error {
+	return f.Stop()
+}
+
+// lastLineWriter keeps the last non-empty line written (ffmpeg error)
+type lastLineWriter struct {
+	line string
+	mu   sync.Mutex
+}
+
+// Write stores last line of p
+func (w *lastLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			w.line = line
+		}
+	}
+	return len(p), nil
+}
+
+// String returns last line
+func (w *lastLineWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.line
+}
+
+// clampLevel limits volume level to 0-100
+func clampLevel(level int) int {
+	if level < 0 {
+		return 0
+	}
+	if level > 100 {
+		return 100
+	}
+	return level
+}
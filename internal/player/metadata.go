@@ -0,0 +1,44 @@
+package player
+
+import "strings"
+
+// TrackInfo contains track information from stream
+type TrackInfo struct {
+	URL    string // Stream the track belongs to
+	Title  string
+	Artist string
+}
+
+// splitArtistTitle splits "Artist - Title" format in StreamTitle
+func splitArtistTitle(info *TrackInfo) {
+	if info.Title != "" && info.Artist == "" {
+		if parts := strings.SplitN(info.Title, " - ", 2); len(parts) == 2 {
+			info.Artist = parts[0]
+			info.Title = parts[1]
+		}
+	}
+}
+
+// metadataFeed delivers track changes to the UI
+// Only the latest update is kept if the UI is slow to read
+type metadataFeed chan TrackInfo
+
+// newMetadataFeed creates an empty feed
+func newMetadataFeed() metadataFeed {
+	return make(metadataFeed, 1)
+}
+
+// push sends StreamTitle of url to the feed
+func (f metadataFeed) push(url, streamTitle string) {
+	info := TrackInfo{URL: url, Title: streamTitle}
+	splitArtistTitle(&info)
+	f.send(info)
+}
+
+// send delivers info, replacing an unread update
+func (f metadataFeed) send(info TrackInfo) {
+	if info.Title == "" {
+		return
+	}
+	sendLatest(f, info)
+}
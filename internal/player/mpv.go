@@ -0,0 +1,403 @@
+package player
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"crr/internal/logger"
+	"crr/internal/proc"
+)
+
+// mpvConnectTimeout is how long to wait for mpv to open its IPC socket
+const mpvConnectTimeout = 3 * time.Second
+
+// Property observer IDs
+const (
+	mpvObserveMetadata = 1
+	mpvObserveIdle     = 2
+	mpvObservePath     = 3
+	mpvObserveCoreIdle = 4
+)
+
+// MPV is a backend that drives one long-lived mpv process over its JSON IPC socket
+// Station changes reuse the same process, so they are gapless and keep volume
+type MPV struct {
+	cmd      *exec.Cmd    // mpv process
+	conn     net.Conn     // IPC connection
+	socket   string       // IPC socket path
+	url      string       // current stream URL
+	volume   int          // stream volume level (0-100)
+	paused   bool         // whether stream is paused
+	idle     bool         // mpv reports nothing loaded
+	path     string       // file mpv is playing now
+	coreIdle bool         // mpv produces no audio (waiting for cache)
+	reported State        // last state sent to events
+	metadata metadataFeed // pushed track updates
+	events   stateFeed    // state changes of current stream
+	mu       sync.Mutex   // race condition protection
+	writeMu  sync.Mutex   // serializes IPC writes
+}
+
+// NewMPV creates a new mpv backend
+// The mpv process is started lazily on first playback
+func NewMPV() *MPV {
+	return &MPV{
+		volume:   100,
+		idle:     true,
+		metadata: newMetadataFeed(),
+		events:   newStateFeed(),
+	}
+}
+
+// Events returns channel with stream state changes
+func (m *MPV) Events() <-chan StateEvent {
+	return m.events
+}
+
+// mpvEvent is a message received from mpv IPC
+type mpvEvent struct {
+	Event string          `json:"event"`
+	ID    int             `json:"id"`
+	Name  string          `json:"name"`
+	Data  json.RawMessage `json:"data"`
+	Error string          `json:"error"`
+	// end-file fields
+	Reason    string `json:"reason"`
+	FileError string `json:"file_error"`
+}
+
+// ensureLocked starts mpv and connects to its IPC socket (call with mutex held)
+func (m *MPV) ensureLocked() error {
+	if m.conn != nil {
+		return nil
+	}
+
+	m.socket = filepath.Join(os.TempDir(), fmt.Sprintf("crr-mpv-%d.sock", os.Getpid()))
+	os.Remove(m.socket)
+
+	m.cmd = exec.Command("mpv",
+		"--idle=yes",
+		"--no-video",
+		"--no-terminal",
+		"--gapless-audio=yes",
+		fmt.Sprintf("--volume=%d", m.volume),
+		"--input-ipc-server="+m.socket,
+	)
+	if err := proc.Start(m.cmd); err != nil {
+		m.cmd = nil
+		return err
+	}
+
+	// Wait until mpv opens the socket
+	deadline := time.Now().Add(mpvConnectTimeout)
+	for {
+		conn, err := net.Dial("unix", m.socket)
+		if err == nil {
+			m.conn = conn
+			break
+		}
+		if time.Now().After(deadline) {
+			m.killLocked()
+			return fmt.Errorf("mpv ipc connect: %w", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	go m.readEvents(m.conn)
+
+	m.send("observe_property", mpvObserveMetadata, "metadata")
+	m.send("observe_property", mpvObserveIdle, "idle-active")
+	m.send("observe_property", mpvObservePath, "path")
+	m.send("observe_property", mpvObserveCoreIdle, "core-idle")
+	return nil
+}
+
+// send writes a command to mpv IPC
+func (m *MPV) send(args ...any) error {
+	if m.conn == nil {
+		return fmt.Errorf("mpv is not running")
+	}
+	line, err := json.Marshal(map[string]any{"command": args})
+	if err != nil {
+		return err
+	}
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	_, err = m.conn.Write(append(line, '\n'))
+	return err
+}
+
+// readEvents reads mpv IPC messages until connection is closed
+func (m *MPV) readEvents(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev mpvEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		if ev.Error != "" && ev.Error != "success" {
+			logger.Log.Printf("mpv: %s", ev.Error)
+			continue
+		}
+
+		switch ev.Event {
+		case "property-change":
+			m.handleProperty(ev)
+		case "end-file":
+			m.handleEndFile(ev)
+		}
+	}
+
+	// Connection lost: mpv exited or was killed
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.conn != conn {
+		return // Closed by us
+	}
+	url := m.url
+	m.killLocked()
+	if url != "" {
+		m.events.report(url, StateFailed, fmt.Errorf("mpv exited"))
+	}
+}
+
+// handleProperty applies observed property change
+func (m *MPV) handleProperty(ev mpvEvent) {
+	if ev.ID == mpvObserveMetadata {
+		m.handleMetadata(ev.Data)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch ev.ID {
+	case mpvObserveIdle:
+		json.Unmarshal(ev.Data, &m.idle)
+	case mpvObservePath:
+		m.path = ""
+		json.Unmarshal(ev.Data, &m.path)
+	case mpvObserveCoreIdle:
+		json.Unmarshal(ev.Data, &m.coreIdle)
+	}
+	m.reportLocked()
+}
+
+// reportLocked derives stream state from observed properties (call with mutex held)
+// Properties may arrive in any order, so state is recomputed on every change
+func (m *MPV) reportLocked() {
+	if m.url == "" || m.path != m.url || m.paused {
+		return
+	}
+	state := StatePlaying
+	if m.coreIdle {
+		state = StateBuffering
+	}
+	if state != m.reported {
+		m.reported = state
+		m.events.report(m.url, state, nil)
+	}
+}
+
+// handleEndFile reports stream that ended or failed to open
+func (m *MPV) handleEndFile(ev mpvEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.url == "" {
+		return
+	}
+	switch {
+	case ev.Reason == "error":
+		m.reported = StateFailed
+		m.events.report(m.url, StateFailed, fmt.Errorf("mpv: %s", ev.FileError))
+	case ev.Reason == "eof" && m.path == m.url:
+		m.reported = StateFailed
+		m.events.report(m.url, StateFailed, errStreamEnded)
+	}
+}
+
+// handleMetadata converts mpv metadata property into TrackInfo
+func (m *MPV) handleMetadata(raw json.RawMessage) {
+	var tags map[string]string
+	if err := json.Unmarshal(raw, &tags); err != nil || len(tags) == 0 {
+		return
+	}
+
+	// mpv keeps tag names as sent by the stream, case varies
+	lower := make(map[string]string, len(tags))
+	for k, v := range tags {
+		lower[strings.ToLower(k)] = v
+	}
+
+	// Tags of the intro chunk (ID3 of the MP3) are not the station's track
+	m.mu.Lock()
+	current := m.url != "" && m.path == m.url
+	info := TrackInfo{URL: m.url, Artist: lower["artist"]}
+	m.mu.Unlock()
+	if !current {
+		return
+	}
+	for _, key := range []string{"streamtitle", "icy-title", "title"} {
+		if v := lower[key]; v != "" {
+			info.Title = v
+			break
+		}
+	}
+	splitArtistTitle(&info)
+	m.metadata.send(info)
+}
+
+// Metadata returns channel with pushed track updates
+func (m *MPV) Metadata() <-chan TrackInfo {
+	return m.metadata
+}
+
+// Play loads stream, replacing current playback
+func (m *MPV) Play(url string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.ensureLocked(); err != nil {
+		return err
+	}
+	m.url = url
+	m.paused = false
+	m.idle = false
+	m.reported = StateConnecting
+	m.send("set_property", "pause", false)
+	return m.send("loadfile", url, "replace")
+}
+
+// PlayWithIntro plays chunk, then continues gaplessly with stream
+func (m *MPV) PlayWithIntro(chunk, url string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.ensureLocked(); err != nil {
+		return err
+	}
+	m.url = url
+	m.paused = false
+	m.idle = false
+	m.reported = StateConnecting
+	m.send("set_property", "pause", false)
+	if err := m.send("loadfile", chunk, "replace"); err != nil {
+		return err
+	}
+	return m.send("loadfile", url, "append-play")
+}
+
+// PlayEffect plays a file in a separate short-lived mpv (louder than stream)
+// Loudness follows current volume level
+func (m *MPV) PlayEffect(path string) error {
+	m.mu.Lock()
+	volume := m.volume
+	m.mu.Unlock()
+
+	cmd := exec.Command("mpv",
+		"--no-video", "--no-terminal",
+		fmt.Sprintf("--volume=%d", volume),
+		"--af=lavfi=[volume="+ChunkVolumeDB+"dB]",
+		path,
+	)
+	return proc.Spawn(cmd)
+}
+
+// Stop stops current playback, mpv itself keeps running
+func (m *MPV) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.url = ""
+	m.paused = false
+	if m.conn == nil {
+		return nil
+	}
+	m.idle = true
+	return m.send("stop")
+}
+
+// SetVolume changes volume of running stream
+func (m *MPV) SetVolume(level int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.volume = clampLevel(level)
+	if m.conn == nil {
+		return nil // Applied on start
+	}
+	return m.send("set_property", "volume", m.volume)
+}
+
+// SetPaused pauses or resumes stream
+func (m *MPV) SetPaused(paused bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conn == nil || m.url == "" {
+		return nil
+	}
+	m.paused = paused
+	return m.send("set_property", "pause", paused)
+}
+
+// State reports current playback state
+func (m *MPV) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case m.url == "" || m.idle:
+		return StateStopped
+	case m.paused:
+		return StatePaused
+	default:
+		return StatePlaying
+	}
+}
+
+// Close quits mpv and removes IPC socket
+func (m *MPV) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conn != nil {
+		m.send("quit")
+	}
+	m.killLocked()
+	return nil
+}
+
+// killLocked terminates mpv process (call with mutex held)
+func (m *MPV) killLocked() {
+	if m.conn != nil {
+		m.conn.Close()
+		m.conn = nil
+	}
+	if m.cmd != nil && m.cmd.Process != nil {
+		done := make(chan struct{})
+		go func(cmd *exec.Cmd) {
+			proc.Wait(cmd)
+			close(done)
+		}(m.cmd)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			proc.Kill(m.cmd)
+			<-done
+		}
+		m.cmd = nil
+	}
+	os.Remove(m.socket)
+	m.url = ""
+	m.idle = true
+}
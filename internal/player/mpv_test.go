@@ -0,0 +1,47 @@
+package player
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestMPVMetadataOfCurrentPath(t *testing.T) {
+	const chunk, stream = "/tmp/chunks/61.mp3", "http://radio.example/live"
+	m := NewMPV()
+	m.url = stream
+	client, server := net.Pipe()
+	defer server.Close()
+	go m.readEvents(client)
+
+	// send writes IPC line; net.Pipe returns once mpv reader took it,
+	// so the line before it has been handled
+	send := func(format string, args ...any) {
+		t.Helper()
+		if _, err := fmt.Fprintf(server, format+"\n", args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := `{"event":"property-change","id":%d,"name":"path","data":%q}`
+	metadata := `{"event":"property-change","id":%d,"name":"metadata","data":{"TITLE":%q,"ARTIST":%q}}`
+
+	send(path, mpvObservePath, chunk)
+	send(metadata, mpvObserveMetadata, "Jingle", "crr")
+	send(path, mpvObservePath, stream)
+	select {
+	case info := <-m.Metadata():
+		t.Fatalf("metadata of intro chunk reported as %+v", info)
+	default:
+	}
+
+	send(metadata, mpvObserveMetadata, "Song", "Band")
+	select {
+	case info := <-m.Metadata():
+		if info.URL != stream || info.Title != "Song" || info.Artist != "Band" {
+			t.Errorf("stream metadata %+v, want Band - Song of %s", info, stream)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream metadata not reported")
+	}
+}
@@ -0,0 +1,232 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"crr/internal/audio"
+)
+
+// SinkSpec selects output of the native backend (see audio.OpenSink)
+var SinkSpec string
+
+// Fade lengths of the native pipeline
+const (
+	nativeStreamFade = 50 * time.Millisecond // Fade-in of a directly played stream
+	nativeIntroFade  = 1 * time.Second       // Crossfade from chunk into stream
+)
+
+// Native is a backend that decodes and mixes audio in-process
+// It needs no ffmpeg, only a PCM sink
+type Native struct {
+	mixer  *audio.Mixer // started lazily on first playback
+	stream *audio.Voice // current stream voice
+	url    string       // current stream URL (for resume)
+	volume int          // stream volume level (0-100)
+	paused bool         // whether stream is paused
+	feed   metadataFeed // ICY titles of current stream
+	events stateFeed    // state changes of current stream
+	mu     sync.Mutex   // race condition protection
+}
+
+// NewNative creates a new in-process backend
+func NewNative() *Native {
+	return &Native{volume: 100, feed: newMetadataFeed(), events: newStateFeed()}
+}
+
+// streamOptions returns voice options for stream at url
+func (n *Native) streamOptions(url string) audio.VoiceOptions {
+	return audio.VoiceOptions{
+		Gain:    1,
+		FadeIn:  nativeStreamFade,
+		OnTitle: func(title string) { n.feed.push(url, title) },
+	}
+}
+
+// Metadata returns channel with ICY title changes
+func (n *Native) Metadata() <-chan TrackInfo {
+	return n.feed
+}
+
+// Events returns channel with stream state changes
+func (n *Native) Events() <-chan StateEvent {
+	return n.events
+}
+
+// playLocked starts stream voice and watches it (call with mutex held)
+func (n *Native) playLocked(url string, opts audio.VoiceOptions) {
+	v := n.mixer.Play(url, opts)
+	n.stream = v
+	n.events.report(url, StateBuffering, nil)
+	go n.watch(v, url)
+}
+
+// watch reports state changes of stream voice while it is current
+func (n *Native) watch(v *audio.Voice, url string) {
+	select {
+	case <-v.Sounding():
+		n.reportIfCurrent(v, url, StatePlaying, nil)
+	case <-v.Done():
+	}
+	<-v.Done()
+
+	err := v.Err()
+	if err == nil {
+		err = errStreamEnded
+	}
+	if errors.Is(err, audio.ErrUnsupportedCodec) {
+		err = fmt.Errorf("%w (play it with the mpv or ffplay backend)", err)
+	}
+	n.reportIfCurrent(v, url, StateFailed, err)
+}
+
+// reportIfCurrent reports state if v is still the current stream
+func (n *Native) reportIfCurrent(v *audio.Voice, url string, state State, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.stream == v {
+		n.events.report(url, state, err)
+	}
+}
+
+// ensureLocked opens sink and starts mixer (call with mutex held)
+func (n *Native) ensureLocked() error {
+	if n.mixer != nil {
+		return nil
+	}
+	sink, err := audio.OpenSink(SinkSpec)
+	if err != nil {
+		return err
+	}
+	n.mixer = audio.NewMixer(sink)
+	n.mixer.SetVolume(float64(n.volume) / 100)
+	return nil
+}
+
+// Play plays stream directly
+func (n *Native) Play(url string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.ensureLocked(); err != nil {
+		return err
+	}
+	n.mixer.Stop(n.stream)
+	n.url = url
+	n.paused = false
+	n.playLocked(url, n.streamOptions(url))
+	return nil
+}
+
+// PlayWithIntro plays chunk, then crossfades into stream
+func (n *Native) PlayWithIntro(chunk, url string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.ensureLocked(); err != nil {
+		return err
+	}
+	n.mixer.Stop(n.stream)
+	n.url = url
+	n.paused = false
+	intro := n.mixer.Play(chunk, audio.VoiceOptions{Gain: 1})
+	opts := n.streamOptions(url)
+	opts.FadeIn = nativeIntroFade
+	opts.After = intro
+	n.playLocked(url, opts)
+	return nil
+}
+
+// PlayEffect mixes a file on top of current playback (louder than stream)
+func (n *Native) PlayEffect(path string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.ensureLocked(); err != nil {
+		return err
+	}
+	n.mixer.Play(path, audio.VoiceOptions{Gain: audio.GainFromDB(ChunkVolumeDB)})
+	return nil
+}
+
+// Stop stops current stream
+func (n *Native) Stop() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.url = ""
+	n.paused = false
+	n.stopLocked()
+	return nil
+}
+
+// stopLocked stops stream voice (call with mutex held)
+func (n *Native) stopLocked() {
+	if n.mixer != nil {
+		n.mixer.Stop(n.stream)
+	}
+	n.stream = nil
+}
+
+// SetVolume changes volume of running stream
+func (n *Native) SetVolume(level int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.volume = clampLevel(level)
+	if n.mixer != nil {
+		n.mixer.SetVolume(float64(n.volume) / 100)
+	}
+	return nil
+}
+
+// SetPaused pauses or resumes stream
+// Live streams cannot be buffered while paused, so pause disconnects
+// and resume reconnects to the same URL
+func (n *Native) SetPaused(paused bool) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if paused == n.paused || n.url == "" || n.mixer == nil {
+		return nil
+	}
+	n.paused = paused
+	if paused {
+		n.stopLocked()
+		return nil
+	}
+	n.playLocked(n.url, n.streamOptions(n.url))
+	return nil
+}
+
+// State reports current playback state
+func (n *Native) State() State {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.paused {
+		return StatePaused
+	}
+	if n.stream == nil {
+		return StateStopped
+	}
+	select {
+	case <-n.stream.Done():
+		return StateStopped
+	default:
+		return StatePlaying
+	}
+}
+
+// Close stops playback and closes sink
+func (n *Native) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stream = nil
+	n.url = ""
+	if n.mixer == nil {
+		return nil
+	}
+	err := n.mixer.Close()
+	n.mixer = nil
+	return err
+}
@@ -0,0 +1,97 @@
+package player
+
+import "sync"
+
+// Null is a backend that produces no sound
+// It keeps track of requested state, so the UI can run headless and in tests
+type Null struct {
+	URL     string // Last requested stream URL
+	Effects int    // Number of played effects
+	Volume  int    // Last set volume level
+	state   State
+	feed    metadataFeed
+	events  stateFeed
+	mu      sync.Mutex
+}
+
+// NewNull creates a silent backend
+func NewNull() *Null {
+	return &Null{Volume: 100, feed: newMetadataFeed(), events: newStateFeed()}
+}
+
+// Play remembers stream URL and switches to playing state
+func (n *Null) Play(url string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.URL = url
+	n.state = StatePlaying
+	n.events.report(url, StatePlaying, nil)
+	return nil
+}
+
+// PlayWithIntro behaves like Play (intro is not played)
+func (n *Null) PlayWithIntro(chunk, url string) error {
+	return n.Play(url)
+}
+
+// PlayEffect counts played effects
+func (n *Null) PlayEffect(path string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.Effects++
+	return nil
+}
+
+// Stop switches to stopped state
+func (n *Null) Stop() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.URL = ""
+	n.state = StateStopped
+	return nil
+}
+
+// SetVolume remembers volume level
+func (n *Null) SetVolume(level int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.Volume = clampLevel(level)
+	return nil
+}
+
+// SetPaused toggles between playing and paused states
+func (n *Null) SetPaused(paused bool) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.state == StateStopped {
+		return nil
+	}
+	if paused {
+		n.state = StatePaused
+	} else {
+		n.state = StatePlaying
+	}
+	return nil
+}
+
+// State reports current playback state
+func (n *Null) State() State {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.state
+}
+
+// Metadata returns channel that never receives (no stream is read)
+func (n *Null) Metadata() <-chan TrackInfo {
+	return n.feed
+}
+
+// Events returns channel with state changes (playback always succeeds)
+func (n *Null) Events() <-chan StateEvent {
+	return n.events
+}
+
+// Close stops playback
+func (n *Null) Close() error {
+	return n.Stop()
+}
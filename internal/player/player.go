@@ -3,31 +3,58 @@ package player
 import (
 	"fmt"
 	"math/rand"
-	"os/exec"
 	"path/filepath"
-	"strings"
 	"sync"
+	"time"
+
+	"crr/internal/proc"
 )
 
-// Volume settings (in dB)
+// ChunkVolumeDB is the chunk volume in dB (+3dB, louder; set from config)
+var ChunkVolumeDB = "3"
+
+// StreamVolumeDB is the stream volume in dB (0dB, normal)
+const StreamVolumeDB = "0"
+
+// Reconnect settings
 const (
-	ChunkVolumeDB  = "3" // Chunk volume (+3dB, louder)
-	StreamVolumeDB = "0" // Stream volume (0dB, normal)
+	MaxReconnects      = 5                // Attempts before giving up on a stream
+	ReconnectBaseDelay = 1 * time.Second  // Delay before first attempt (doubles each time)
+	ReconnectMaxDelay  = 30 * time.Second // Upper bound for the delay
 )
 
 // Player manages audio playback
+// Actual audio output is delegated to a Backend. Player supervises it:
+// state changes are forwarded to Events and dropped streams are reconnected
 type Player struct {
-	ffmpeg    *exec.Cmd  // ffmpeg process (crossfade)
-	ffplay    *exec.Cmd  // ffplay process (playback)
-	chunksDir string     // path to chunks folder
-	mu        sync.Mutex // race condition protection
+	backend   Backend       // audio engine
+	chunksDir string        // path to chunks folder
+	want      string        // stream URL that should be playing
+	attempt   int           // reconnect attempts for want
+	retry     *time.Timer   // pending reconnect
+	state     State         // last reported state of want
+	events    stateFeed     // state changes for the UI
+	done      chan struct{} // closed by Cleanup, ends supervision
+	closeOnce sync.Once     // closes done once
+	playMu    sync.Mutex    // serializes backend playback calls
+	mu        sync.Mutex    // protects supervision state
 }
 
-// New creates a new Player
-func New(chunksDir string) *Player {
-	return &Player{
+// New creates a new Player on top of backend
+func New(backend Backend, chunksDir string) *Player {
+	p := &Player{
+		backend:   backend,
 		chunksDir: chunksDir,
+		events:    newStateFeed(),
+		done:      make(chan struct{}),
 	}
+	go p.supervise()
+	return p
+}
+
+// Backend returns the audio engine used by the player
+func (p *Player) Backend() Backend {
+	return p.backend
 }
 
 // getRandomChunk returns path to a random chunk file
@@ -40,105 +67,9 @@ func (p *Player) getRandomChunk() (string, error) {
 }
 
 // PlayStream plays stream with crossfade from chunk
-// ffmpeg -i chunk.mp3 -i stream_url -filter_complex "acrossfade=d=0" -f matroska - | ffplay -
 func (p *Player) PlayStream(url string) error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	// Stop current playback
-	p.stopLocked()
-
-	// Get random chunk
-	chunk, err := p.getRandomChunk()
-	if err != nil {
-		// If no chunks - just play stream directly
-		return p.playDirectLocked(url)
-	}
-
-	// ffmpeg: crossfade chunk → stream
-	p.ffmpeg = exec.Command("ffmpeg",
-		"-i", chunk,
-		"-i", url,
-		"-filter_complex", "[0:a]apad[a0];[a0][1:a]acrossfade=d=1:c1=tri:c2=tri",
-		"-f", "matroska",
-		"-loglevel", "quiet",
-		"-",
-	)
-
-	// ffplay: playback from pipe
-	p.ffplay = exec.Command("ffplay",
-		"-nodisp",
-		"-loglevel", "quiet",
-		"-i", "-",
-	)
-
-	// Connect ffmpeg stdout → ffplay stdin
-	pipe, err := p.ffmpeg.StdoutPipe()
-	if err != nil {
-		return err
-	}
-	p.ffplay.Stdin = pipe
-
-	// Start both processes
-	if err := p.ffmpeg.Start(); err != nil {
-		return err
-	}
-	if err := p.ffplay.Start(); err != nil {
-		p.ffmpeg.Process.Kill()
-		return err
-	}
-
-	return nil
-}
-
-// PlayChunkThenStream plays chunk immediately, then connects to stream
-func (p *Player) PlayChunkThenStream(url string) error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	// Stop current playback
-	p.stopLocked()
-
-	// Get random chunk
-	chunk, err := p.getRandomChunk()
-	if err != nil {
-		return p.playDirectLocked(url)
-	}
-
-	// Start ffmpeg with concat: chunk first, then stream
-	// Using concat demuxer via pipe
-	p.ffmpeg = exec.Command("ffmpeg",
-		"-i", chunk, // chunk as first input
-		"-i", url, // stream as second input
-		"-filter_complex",
-		"[0:a]apad=pad_dur=1.2[a0];[a0][1:a]acrossfade=d=1:c1=tri:c2=tri[out]",
-		"-map", "[out]",
-		"-f", "matroska",
-		"-loglevel", "quiet",
-		"-",
-	)
-
-	p.ffplay = exec.Command("ffplay",
-		"-nodisp",
-		"-loglevel", "quiet",
-		"-i", "-",
-	)
-
-	pipe, err := p.ffmpeg.StdoutPipe()
-	if err != nil {
-		return err
-	}
-	p.ffplay.Stdin = pipe
-
-	if err := p.ffmpeg.Start(); err != nil {
-		return err
-	}
-	if err := p.ffplay.Start(); err != nil {
-		p.ffmpeg.Process.Kill()
-		return err
-	}
-
-	return nil
+	p.setWant(url)
+	return p.play(url, true)
 }
 
 // PlayChunkImmediately instantly starts chunk playback (separate process, louder)
@@ -147,178 +78,204 @@ func (p *Player) PlayChunkImmediately() error {
 	if err != nil {
 		return err
 	}
-	// Start separate ffplay for chunk with increased volume
-	cmd := exec.Command("ffplay",
-		"-nodisp", "-autoexit", "-loglevel", "quiet",
-		"-af", "volume="+ChunkVolumeDB+"dB",
-		chunk,
-	)
-	return cmd.Start()
+	return p.backend.PlayEffect(chunk)
 }
 
 // SwitchStation plays instant chunk + connects to stream
 func (p *Player) SwitchStation(url string) error {
 	// 1. Stop current stream immediately
 	p.Stop()
+	p.setWant(url)
 
 	// 2. Instantly start chunk (separate process)
 	p.PlayChunkImmediately()
 
 	// 3. Start connecting to new stream (in background)
-	go func() {
-		p.mu.Lock()
-		defer p.mu.Unlock()
-		p.playDirectLocked(url)
-	}()
+	go p.play(url, false)
 
 	return nil
 }
 
-// playDirectLocked plays stream directly without crossfade (fallback)
-func (p *Player) playDirectLocked(url string) error {
-	p.ffplay = exec.Command("ffplay",
-		"-nodisp", "-loglevel", "quiet",
-		"-af", "volume="+StreamVolumeDB+"dB",
-		url,
-	)
-	return p.ffplay.Start()
-}
-
 // PlayChunk plays only chunk (without stream, with increased volume)
 func (p *Player) PlayChunk() error {
-	chunk, err := p.getRandomChunk()
-	if err != nil {
-		return err
+	return p.PlayChunkImmediately()
+}
+
+// play starts url on backend, unless another stream was requested meanwhile
+// Errors are reported to supervision as StateFailed
+func (p *Player) play(url string, intro bool) error {
+	p.playMu.Lock()
+	defer p.playMu.Unlock()
+
+	if !p.wanted(url) {
+		return nil // Superseded while waiting
 	}
 
-	cmd := exec.Command("ffplay",
-		"-nodisp", "-autoexit", "-loglevel", "quiet",
-		"-af", "volume="+ChunkVolumeDB+"dB",
-		chunk,
-	)
-	return cmd.Start()
+	var err error
+	chunk, chunkErr := p.getRandomChunk()
+	if intro && chunkErr == nil {
+		err = p.backend.PlayWithIntro(chunk, url)
+	} else {
+		// If no chunks - just play stream directly
+		err = p.backend.Play(url)
+	}
+	if err != nil {
+		p.handle(StateEvent{URL: url, State: StateFailed, Err: err})
+	}
+	return err
 }
 
 // Stop stops current playback
 func (p *Player) Stop() error {
+	p.setWant("")
+	p.playMu.Lock()
+	defer p.playMu.Unlock()
+	return p.backend.Stop()
+}
+
+// setWant records stream that should be playing and resets reconnects
+func (p *Player) setWant(url string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	return p.stopLocked()
-}
 
-// stopLocked is internal stop method (call with mutex held)
-func (p *Player) stopLocked() error {
-	if p.ffplay != nil && p.ffplay.Process != nil {
-		p.ffplay.Process.Kill()
-		p.ffplay = nil
+	p.want = url
+	p.attempt = 0
+	if p.retry != nil {
+		p.retry.Stop()
+		p.retry = nil
 	}
-	if p.ffmpeg != nil && p.ffmpeg.Process != nil {
-		p.ffmpeg.Process.Kill()
-		p.ffmpeg = nil
+	if url == "" {
+		p.state = StateStopped
+		return
 	}
-	return nil
+	p.emitLocked(StateEvent{URL: url, State: StateConnecting})
 }
 
-// IsPlaying checks if stream is playing
-func (p *Player) IsPlaying() bool {
+// wanted reports whether url is the stream that should be playing
+func (p *Player) wanted(url string) bool {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	return p.ffplay != nil && p.ffplay.Process != nil
+	return url != "" && url == p.want
 }
 
-// Cleanup terminates all processes on exit
-func (p *Player) Cleanup() {
-	p.Stop()
-	// Kill all remaining processes
-	exec.Command("pkill", "-f", "ffplay").Run()
-	exec.Command("pkill", "-f", "ffmpeg").Run()
-}
-
-// TrackInfo contains track information from stream
-type TrackInfo struct {
-	Title  string
-	Artist string
-}
-
-// GetStreamMetadata fetches metadata from stream via ffprobe
-func GetStreamMetadata(url string) (*TrackInfo, error) {
-	// ffprobe -v quiet -show_entries format_tags=StreamTitle,icy-title,title,artist
-	// -of default=nw=1:nk=1 "url"
-	ctx, cancel := exec.Command("ffprobe",
-		"-v", "quiet",
-		"-show_entries", "format_tags=StreamTitle,icy-title,title,artist",
-		"-of", "json",
-		"-i", url,
-	).Output()
-	cancel = nil
-	_ = cancel
-
-	if len(ctx) == 0 {
-		return nil, fmt.Errorf("no metadata")
+// supervise handles backend state changes until Cleanup
+// (backends do not close their events channel)
+func (p *Player) supervise() {
+	events := p.backend.Events()
+	for {
+		select {
+		case ev := <-events:
+			p.handle(ev)
+		case <-p.done:
+			return
+		}
 	}
-
-	// Parse JSON response
-	return parseMetadata(ctx)
 }
 
-// parseMetadata parses JSON from ffprobe
-func parseMetadata(data []byte) (*TrackInfo, error) {
-	// Simple parsing - look for StreamTitle or title
-	str := string(data)
-	info := &TrackInfo{}
-
-	// Look for StreamTitle (ICY metadata)
-	if idx := strings.Index(str, "StreamTitle"); idx != -1 {
-		info.Title = extractJSONValue(str[idx:])
-	} else if idx := strings.Index(str, "icy-title"); idx != -1 {
-		info.Title = extractJSONValue(str[idx:])
-	} else if idx := strings.Index(str, "title"); idx != -1 {
-		info.Title = extractJSONValue(str[idx:])
-	}
+// handle forwards backend event and schedules reconnects on failure
+func (p *Player) handle(ev StateEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	// Look for artist
-	if idx := strings.Index(str, "artist"); idx != -1 {
-		info.Artist = extractJSONValue(str[idx:])
+	if ev.URL == "" || ev.URL != p.want {
+		return // Event of a stream nobody listens to anymore
 	}
 
-	// If "Artist - Title" format in StreamTitle
-	if info.Title != "" && info.Artist == "" {
-		if parts := strings.SplitN(info.Title, " - ", 2); len(parts) == 2 {
-			info.Artist = parts[0]
-			info.Title = parts[1]
+	switch ev.State {
+	case StateFailed:
+		if p.attempt >= MaxReconnects {
+			p.emitLocked(ev)
+			return
 		}
-	}
+		p.attempt++
+		p.emitLocked(StateEvent{URL: ev.URL, State: StateReconnecting, Err: ev.Err, Attempt: p.attempt})
+		url := ev.URL
+		p.retry = time.AfterFunc(reconnectDelay(p.attempt), func() {
+			p.play(url, false)
+		})
+
+	case StatePlaying:
+		p.attempt = 0
+		p.emitLocked(ev)
+
+	case StateConnecting, StateBuffering:
+		if p.attempt > 0 {
+			return // Still reconnecting until audio plays
+		}
+		p.emitLocked(ev)
 
-	if info.Title == "" {
-		return nil, fmt.Errorf("no title found")
+	default:
+		p.emitLocked(ev)
 	}
+}
 
-	return info, nil
+// emitLocked records and publishes event (call with mu held)
+func (p *Player) emitLocked(ev StateEvent) {
+	p.state = ev.State
+	p.events.send(ev)
 }
 
-// extractJSONValue extracts value from JSON string
-func extractJSONValue(s string) string {
-	// Look for ": "value"
-	start := strings.Index(s, "\":")
-	if start == -1 {
-		return ""
+// reconnectDelay returns exponential backoff delay for attempt (1-based)
+func reconnectDelay(attempt int) time.Duration {
+	delay := ReconnectBaseDelay << (attempt - 1)
+	if delay > ReconnectMaxDelay || delay <= 0 {
+		return ReconnectMaxDelay
 	}
-	s = s[start+2:]
+	return delay
+}
 
-	// Skip whitespace
-	s = strings.TrimLeft(s, " ")
+// SetVolume sets stream volume level (0-100)
+func (p *Player) SetVolume(level int) error {
+	return p.backend.SetVolume(level)
+}
 
-	// Find value start
-	if len(s) == 0 || s[0] != '"' {
-		return ""
+// SetPaused pauses or resumes stream
+func (p *Player) SetPaused(paused bool) error {
+	if err := p.backend.SetPaused(paused); err != nil {
+		return err
 	}
-	s = s[1:]
 
-	// Find value end
-	end := strings.Index(s, "\"")
-	if end == -1 {
-		return ""
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.want != "" {
+		state := StatePlaying
+		if paused {
+			state = StatePaused
+		}
+		p.emitLocked(StateEvent{URL: p.want, State: state})
 	}
+	return nil
+}
+
+// Metadata returns channel with pushed track updates
+func (p *Player) Metadata() <-chan TrackInfo {
+	return p.backend.Metadata()
+}
 
-	return s[:end]
+// Events returns channel with state changes of the wanted stream
+func (p *Player) Events() <-chan StateEvent {
+	return p.events
+}
+
+// State reports current playback state
+func (p *Player) State() State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+// IsPlaying checks if stream is playing
+func (p *Player) IsPlaying() bool {
+	return p.State() == StatePlaying
+}
+
+// Cleanup terminates all processes on exit
+// Only children started by crr are killed (see package proc)
+func (p *Player) Cleanup() {
+	p.closeOnce.Do(func() { close(p.done) })
+	p.setWant("")
+	p.playMu.Lock()
+	defer p.playMu.Unlock()
+	p.backend.Close()
+	proc.KillAll()
 }
@@ -0,0 +1,78 @@
+// Package proc tracks child processes started by crr
+// Every child runs in its own process group, so teardown kills the child
+// together with anything it spawned, and never touches unrelated processes
+package proc
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+)
+
+var (
+	running = map[*exec.Cmd]struct{}{} // started and not yet waited children
+	closed  bool                       // KillAll was called, no new children
+	mu      sync.Mutex
+)
+
+// ErrClosed is returned by Start after KillAll
+var ErrClosed = errors.New("proc: shutting down")
+
+// Start starts cmd in its own process group and tracks it until Wait
+func Start(cmd *exec.Cmd) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if closed {
+		return ErrClosed
+	}
+	setGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	running[cmd] = struct{}{}
+	return nil
+}
+
+// Spawn starts cmd and reaps it in background (fire-and-forget children)
+func Spawn(cmd *exec.Cmd) error {
+	if err := Start(cmd); err != nil {
+		return err
+	}
+	go Wait(cmd)
+	return nil
+}
+
+// Wait waits for cmd to exit and stops tracking it
+func Wait(cmd *exec.Cmd) error {
+	err := cmd.Wait()
+	mu.Lock()
+	delete(running, cmd)
+	mu.Unlock()
+	return err
+}
+
+// Kill kills process group of cmd (no-op if cmd is not started or
+// already waited, as its pid may have been reused)
+func Kill(cmd *exec.Cmd) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := running[cmd]; ok {
+		killGroup(cmd)
+	}
+}
+
+// KillAll kills all tracked children and refuses to start new ones
+// Called once on exit
+func KillAll() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	closed = true
+	for cmd := range running {
+		killGroup(cmd)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package proc
+
+import "os/exec"
+
+// setGroup does nothing, process groups are unix-only
+func setGroup(cmd *exec.Cmd) {}
+
+// killGroup kills cmd itself
+func killGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
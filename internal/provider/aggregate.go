@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"crr/internal/data"
+	"crr/internal/logger"
+)
+
+// Aggregate merges several providers into one
+// Providers are asked in parallel; earlier ones win when stations share a
+// stream URL. A failing provider is skipped as long as another one answers
+type Aggregate struct {
+	Providers []StationProvider
+}
+
+// New returns provider merging providers (the provider itself if there is one)
+func New(providers ...StationProvider) StationProvider {
+	if len(providers) == 1 {
+		return providers[0]
+	}
+	return &Aggregate{Providers: providers}
+}
+
+// Name returns names of merged providers ("radiobrowser+icecast")
+func (a *Aggregate) Name() string {
+	names := make([]string, len(a.Providers))
+	for i, p := range a.Providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// result is the answer of one provider
+type result[T any] struct {
+	value T
+	more  bool
+	err   error
+}
+
+// each calls fn for every provider in parallel, returning successful
+// answers in provider order; err is set only if no provider answered
+func each[T any](a *Aggregate, what string, fn func(p StationProvider) (T, bool, error)) ([]result[T], error) {
+	results := make([]result[T], len(a.Providers))
+	var wg sync.WaitGroup
+	for i, p := range a.Providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, more, err := fn(p)
+			results[i] = result[T]{value: v, more: more, err: err}
+		}()
+	}
+	wg.Wait()
+
+	var answered []result[T]
+	var errs []error
+	for i, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.Providers[i].Name(), r.err))
+			continue
+		}
+		answered = append(answered, r)
+	}
+	if len(answered) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		logger.Log.Printf("Skipping provider in %s: %v", what, err)
+	}
+	return answered, nil
+}
+
+// Categories returns categories of all providers, adding up station counts
+// of the same country (by code), tag and language (by name in any case)
+func (a *Aggregate) Categories(ctx context.Context) (data.Directory, error) {
+	results, err := each(a, "categories", func(p StationProvider) (data.Directory, bool, error) {
+		dir, err := p.Categories(ctx)
+		return dir, false, err
+	})
+	if err != nil {
+		return data.Directory{}, err
+	}
+	var countries, tags, languages [][]data.Category
+	for _, r := range results {
+		countries = append(countries, r.value.Countries)
+		tags = append(tags, r.value.Tags)
+		languages = append(languages, r.value.Languages)
+	}
+	return data.Directory{
+		Countries: mergeCategories(countries...),
+		Tags:      mergeCategories(tags...),
+		Languages: mergeCategories(languages...),
+	}, nil
+}
+
+// States returns states of country listed by any provider
+func (a *Aggregate) States(ctx context.Context, country string) ([]data.Category, error) {
+	results, err := each(a, "states", func(p StationProvider) ([]data.Category, bool, error) {
+		states, err := p.States(ctx, country)
+		return states, false, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var lists [][]data.Category
+	for _, r := range results {
+		lists = append(lists, r.value)
+	}
+	return mergeCategories(lists...), nil
+}
+
+// Stations returns page of every provider; more while any provider has more
+func (a *Aggregate) Stations(ctx context.Context, q StationQuery, page int, f Filter) ([]data.Station, bool, error) {
+	results, err := each(a, "stations", func(p StationProvider) ([]data.Station, bool, error) {
+		return p.Stations(ctx, q, page, f)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	var lists [][]data.Station
+	more := false
+	for _, r := range results {
+		lists = append(lists, r.value)
+		more = more || r.more
+	}
+	return mergeStations(lists...), more, nil
+}
+
+// Search returns matches of every provider
+func (a *Aggregate) Search(ctx context.Context, q SearchQuery, f Filter) ([]data.Station, error) {
+	results, err := each(a, "search", func(p StationProvider) ([]data.Station, bool, error) {
+		stations, err := p.Search(ctx, q, f)
+		return stations, false, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var lists [][]data.Station
+	for _, r := range results {
+		lists = append(lists, r.value)
+	}
+	return mergeStations(lists...), nil
+}
+
+// Resolve asks the provider that listed station (playlists are expanded
+// for stations of unknown source)
+func (a *Aggregate) Resolve(ctx context.Context, st data.Station) (string, error) {
+	for _, p := range a.Providers {
+		if p.Name() == st.Source {
+			return p.Resolve(ctx, st)
+		}
+	}
+	return ResolveLink(ctx, st.Link)
+}
+
+// mergeStations concatenates lists, dropping stations whose stream
+// was listed before
+func mergeStations(lists ...[]data.Station) []data.Station {
+	seen := map[string]bool{}
+	var out []data.Station
+	for _, list := range lists {
+		for _, s := range list {
+			key := StreamKey(s.Link)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// mergeCategories concatenates lists, adding station counts of
+// categories with the same code (or name in any case) to the first one
+func mergeCategories(lists ...[]data.Category) []data.Category {
+	index := map[string]int{}
+	var out []data.Category
+	for _, list := range lists {
+		for _, c := range list {
+			key := strings.ToUpper(c.Code)
+			if key == "" {
+				key = strings.ToLower(c.Name)
+			}
+			if i, ok := index[key]; ok {
+				out[i].Stations += c.Stations
+				continue
+			}
+			index[key] = len(out)
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,339 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crr/internal/cache"
+	"crr/internal/data"
+	"crr/internal/logger"
+)
+
+// DefaultIcecastURL is the Xiph Icecast directory
+const DefaultIcecastURL = "https://dir.xiph.org/yp.xml"
+
+// IcecastURL is the directory read by the provider named "icecast" (set from config)
+var IcecastURL = DefaultIcecastURL
+
+// ypLimit caps the size of a downloaded yp.xml
+const ypLimit = 64 << 20
+
+// Icecast lists stations of an Icecast directory in yp.xml format
+// The directory knows genres only: stations are found by tag and name,
+// queries for a country, state or language find nothing
+// The downloaded directory is cached for cache.ListingTTL
+type Icecast struct {
+	URL string // yp.xml URL (DefaultIcecastURL if empty)
+
+	mu       sync.Mutex
+	stations []data.Station // Directory read last
+	loaded   time.Time      // When stations were read (from cache or URL)
+}
+
+// NewIcecast returns provider reading directory from url
+func NewIcecast(url string) *Icecast {
+	return &Icecast{URL: url}
+}
+
+// Name returns "icecast"
+func (p *Icecast) Name() string {
+	return "icecast"
+}
+
+// Categories returns up to TagLimit genres, most stations first
+func (p *Icecast) Categories(ctx context.Context) (data.Directory, error) {
+	stations, err := p.load(ctx)
+	if err != nil {
+		return data.Directory{}, err
+	}
+
+	counts := map[string]int{}
+	for _, st := range stations {
+		for _, tag := range st.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]data.Category, 0, len(counts))
+	for name, n := range counts {
+		tags = append(tags, data.Category{Name: name, Stations: n})
+	}
+	slices.SortFunc(tags, func(a, b data.Category) int {
+		return cmp.Or(b.Stations-a.Stations, strings.Compare(a.Name, b.Name))
+	})
+	if len(tags) > TagLimit {
+		tags = tags[:TagLimit]
+	}
+	return data.Directory{Tags: tags}, nil
+}
+
+// States returns nothing (the directory lists no locations)
+func (p *Icecast) States(ctx context.Context, country string) ([]data.Category, error) {
+	return nil, nil
+}
+
+// Stations returns page of stations tagged with the tag of q
+func (p *Icecast) Stations(ctx context.Context, q StationQuery, page int, f Filter) ([]data.Station, bool, error) {
+	if q.IsEmpty() {
+		return nil, false, fmt.Errorf("station query is empty")
+	}
+	if q.CountryCode != "" || q.State != "" || q.Language != "" {
+		return nil, false, nil
+	}
+	stations, err := p.load(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var found []data.Station
+	for _, st := range stations {
+		if hasTag(st, q.Tag) && matchesFilter(st, f) {
+			found = append(found, st)
+		}
+	}
+	sortStations(found, f)
+
+	from := min(page*StationLimit, len(found))
+	to := min(from+StationLimit, len(found))
+	return found[from:to], to < len(found), nil
+}
+
+// Search returns up to SearchLimit stations whose name contains
+// the name of q (in any case), tagged with its tag
+func (p *Icecast) Search(ctx context.Context, q SearchQuery, f Filter) ([]data.Station, error) {
+	if q.IsEmpty() {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	if strings.TrimSpace(q.Language) != "" {
+		return nil, nil
+	}
+	stations, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSpace(q.Name))
+	var found []data.Station
+	for _, st := range stations {
+		if strings.Contains(strings.ToLower(st.Name), name) && hasTag(st, q.Tag) && matchesFilter(st, f) {
+			found = append(found, st)
+		}
+	}
+	sortStations(found, f)
+	if len(found) > SearchLimit {
+		found = found[:SearchLimit]
+	}
+	return found, nil
+}
+
+// Resolve returns listen URL of station, expanding playlists
+func (p *Icecast) Resolve(ctx context.Context, st data.Station) (string, error) {
+	return ResolveLink(ctx, st.Link)
+}
+
+// url returns directory URL
+func (p *Icecast) url() string {
+	if p.URL == "" {
+		return DefaultIcecastURL
+	}
+	return p.URL
+}
+
+// load returns stations of the directory, read from the disk cache while
+// fresh and downloaded otherwise (a stale cache is used if that fails)
+func (p *Icecast) load(ctx context.Context) ([]data.Station, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stations != nil && time.Since(p.loaded) < cache.ListingTTL {
+		return p.stations, nil
+	}
+
+	cached, saved, err := cache.LoadListing(p.url())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Log.Printf("Load Icecast directory cache: %v", err)
+	}
+	if err == nil && time.Since(saved) < cache.ListingTTL {
+		p.stations, p.loaded = cached, saved
+		return cached, nil
+	}
+
+	stations, err := p.download(ctx)
+	if err != nil {
+		if len(cached) == 0 {
+			return nil, err
+		}
+		// Stale beats nothing; try again after cache.ListingTTL
+		logger.Log.Printf("Using stale Icecast directory: %v", err)
+		stations = cached
+	} else if err := cache.SaveListing(p.url(), stations); err != nil {
+		logger.Log.Printf("Save Icecast directory cache: %v", err)
+	}
+	p.stations, p.loaded = stations, time.Now()
+	return stations, nil
+}
+
+// ypEntry is a station of yp.xml
+type ypEntry struct {
+	ServerName  string `xml:"server_name"`
+	ListenURL   string `xml:"listen_url"`
+	ServerType  string `xml:"server_type"` // MIME type (audio/mpeg)
+	Bitrate     string `xml:"bitrate"`     // kbps, sometimes a quality ("Quality 0.6")
+	Genre       string `xml:"genre"`       // Genres separated by spaces or commas
+	CurrentSong string `xml:"current_song"`
+}
+
+// download reads the directory from its URL
+func (p *Icecast) download(ctx context.Context) ([]data.Station, error) {
+	body, err := get(ctx, p.url())
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return parseYP(io.LimitReader(body, ypLimit))
+}
+
+// parseYP returns stations of a yp.xml directory in directory order,
+// skipping entries without listen URL and repeated streams
+func parseYP(r io.Reader) ([]data.Station, error) {
+	var dir struct {
+		Entries []ypEntry `xml:"entry"`
+	}
+	if err := xml.NewDecoder(r).Decode(&dir); err != nil {
+		return nil, fmt.Errorf("parse yp.xml: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var stations []data.Station
+	for _, e := range dir.Entries {
+		link := strings.TrimSpace(e.ListenURL)
+		key := StreamKey(link)
+		if link == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		name := strings.TrimSpace(e.ServerName)
+		if name == "" {
+			name = link
+		}
+		stations = append(stations, data.Station{
+			Name:    name,
+			Link:    link,
+			Source:  "icecast",
+			Tags:    ypTags(e.Genre),
+			Codec:   ypCodec(e.ServerType),
+			Bitrate: ypBitrate(e.Bitrate),
+			Song:    strings.TrimSpace(e.CurrentSong),
+		})
+	}
+	return stations, nil
+}
+
+// ypTags returns genres in lower case without repeats: every genre as
+// written ("classic rock") followed by its words ("classic", "rock")
+func ypTags(genre string) []string {
+	var tags []string
+	add := func(tag string) {
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	phrases := strings.FieldsFunc(strings.ToLower(genre), func(r rune) bool {
+		return r == ',' || r == ';' || r == '/' || r == '|'
+	})
+	for _, phrase := range phrases {
+		add(normalizeTag(phrase))
+	}
+	for _, phrase := range phrases {
+		for _, word := range strings.Fields(phrase) {
+			add(word)
+		}
+	}
+	return tags
+}
+
+// normalizeTag returns tag in lower case with single spaces between words
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// ypCodecs maps server types to codec names used by Radio Browser
+var ypCodecs = map[string]string{
+	"audio/mpeg":      "MP3",
+	"audio/mp3":       "MP3",
+	"audio/aac":       "AAC",
+	"audio/aacp":      "AAC+",
+	"audio/ogg":       "OGG",
+	"application/ogg": "OGG",
+	"audio/opus":      "OPUS",
+	"audio/flac":      "FLAC",
+	"video/webm":      "WEBM",
+}
+
+// ypCodec returns codec of server type ("audio/mpeg": "MP3")
+func ypCodec(serverType string) string {
+	t, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(serverType)), ";")
+	if codec, ok := ypCodecs[t]; ok {
+		return codec
+	}
+	_, sub, _ := strings.Cut(t, "/")
+	return strings.ToUpper(strings.TrimPrefix(sub, "x-"))
+}
+
+// ypBitrate returns bitrate in kbps (0 for qualities and garbage)
+func ypBitrate(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// hasTag reports whether station is tagged with tag (any station for no tag)
+// Multi-word tags match genres as written ("classic rock")
+func hasTag(st data.Station, tag string) bool {
+	tag = normalizeTag(tag)
+	return tag == "" || slices.Contains(st.Tags, tag)
+}
+
+// matchesFilter reports whether station passes quality filters of f
+// Stations of unknown language never match a language filter
+func matchesFilter(st data.Station, f Filter) bool {
+	if codec := strings.TrimSpace(f.Codec); codec != "" && !strings.EqualFold(st.Codec, codec) {
+		return false
+	}
+	if st.Bitrate < f.MinBitrate {
+		return false
+	}
+	if f.HTTPSOnly && !strings.HasPrefix(strings.ToLower(st.Link), "https://") {
+		return false
+	}
+	if lang := strings.TrimSpace(f.Language); lang != "" && !strings.EqualFold(st.Language, lang) {
+		return false
+	}
+	return true
+}
+
+// sortStations orders stations by name or bitrate as f asks
+// (other orders need statistics the directory lacks: directory order is kept)
+func sortStations(stations []data.Station, f Filter) {
+	switch f.Sort {
+	case SortName:
+		slices.SortStableFunc(stations, func(a, b data.Station) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		})
+	case SortBitrate:
+		slices.SortStableFunc(stations, func(a, b data.Station) int {
+			return b.Bitrate - a.Bitrate
+		})
+	}
+}
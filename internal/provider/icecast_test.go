@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"crr/internal/cache"
+	"crr/internal/data"
+)
+
+// ypServer serves testdata/yp.xml, failing while fail is set
+type ypServer struct {
+	*httptest.Server
+	hits atomic.Int32 // Requests served
+	fail atomic.Bool  // Answer with an error
+}
+
+// newYPServer starts fixture server and keeps cache files in a temporary directory
+func newYPServer(t *testing.T) *ypServer {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	fixture, err := os.ReadFile("testdata/yp.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &ypServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		if s.fail.Load() {
+			http.Error(w, "directory down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write(fixture)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// names returns station names
+func names(stations []data.Station) []string {
+	out := make([]string, len(stations))
+	for i, st := range stations {
+		out[i] = st.Name
+	}
+	return out
+}
+
+func TestIcecastParse(t *testing.T) {
+	srv := newYPServer(t)
+	p := NewIcecast(srv.URL + "/yp.xml")
+
+	stations, more, err := p.Stations(context.Background(), StationQuery{Tag: "Ambient"}, 0, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if more {
+		t.Error("more = true for the only page")
+	}
+	// The mirror repeats the first stream, the last entry has none
+	if len(stations) != 1 {
+		t.Fatalf("ambient stations = %q, want one", names(stations))
+	}
+	want := data.Station{
+		Name:    "Underground Ambient",
+		Link:    "http://ice.example.org/ambient",
+		Source:  "icecast",
+		Tags:    []string{"ambient drone", "ambient", "drone"},
+		Codec:   "MP3",
+		Bitrate: 128,
+		Song:    "Artist A - Slow Tide",
+	}
+	got := stations[0]
+	if got.Name != want.Name || got.Link != want.Link || got.Source != want.Source ||
+		!slices.Equal(got.Tags, want.Tags) || got.Codec != want.Codec || got.Bitrate != want.Bitrate || got.Song != want.Song {
+		t.Errorf("station = %+v\nwant %+v", got, want)
+	}
+
+	rock, _, err := p.Stations(context.Background(), StationQuery{Tag: "classic rock"}, 0, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rock) != 1 || rock[0].Codec != "OGG" || rock[0].Bitrate != 0 {
+		t.Errorf("classic rock = %+v, want one OGG station of unknown bitrate", rock)
+	}
+
+	jazz, err := p.Search(context.Background(), SearchQuery{Name: "jazz.example"}, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jazz) != 1 || jazz[0].Name != "http://jazz.example.org:8000/jazz" || jazz[0].Codec != "AAC" {
+		t.Errorf("jazz search = %+v, want nameless AAC station named by its URL", jazz)
+	}
+
+	dir, err := p.Categories(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ambient := slices.Index(dir.Tags, data.Category{Name: "ambient", Stations: 1})
+	classicRock := slices.Index(dir.Tags, data.Category{Name: "classic rock", Stations: 1})
+	if ambient < 0 || classicRock < 0 {
+		t.Errorf("tags = %+v, want ambient and classic rock with one station", dir.Tags)
+	}
+	if len(dir.Countries) != 0 {
+		t.Errorf("countries = %+v, directory lists none", dir.Countries)
+	}
+
+	located, _, err := p.Stations(context.Background(), StationQuery{CountryCode: "DE", Tag: "ambient"}, 0, Filter{})
+	if err != nil || len(located) != 0 {
+		t.Errorf("country query = %q, %v; want nothing", names(located), err)
+	}
+}
+
+func TestIcecastDiskCache(t *testing.T) {
+	srv := newYPServer(t)
+	url := srv.URL + "/yp.xml"
+
+	first, err := NewIcecast(url).Categories(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A new provider (next start) reads the saved directory
+	second, err := NewIcecast(url).Categories(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.hits.Load(); n != 1 {
+		t.Errorf("directory downloaded %d times, want once", n)
+	}
+	if !slices.Equal(first.Tags, second.Tags) {
+		t.Errorf("cached tags = %+v, want %+v", second.Tags, first.Tags)
+	}
+	if _, _, err := cache.LoadListing(url); err != nil {
+		t.Errorf("load listing: %v", err)
+	}
+}
+
+func TestIcecastStaleFallback(t *testing.T) {
+	srv := newYPServer(t)
+	url := srv.URL + "/yp.xml"
+	if _, err := NewIcecast(url).Categories(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ttl := cache.ListingTTL
+	cache.ListingTTL = time.Nanosecond
+	t.Cleanup(func() { cache.ListingTTL = ttl })
+	srv.fail.Store(true)
+
+	stations, _, err := NewIcecast(url).Stations(context.Background(), StationQuery{Tag: "jazz"}, 0, Filter{})
+	if err != nil {
+		t.Fatalf("stale directory not used: %v", err)
+	}
+	if len(stations) != 1 {
+		t.Errorf("jazz stations = %q, want one from stale cache", names(stations))
+	}
+	if n := srv.hits.Load(); n != 2 {
+		t.Errorf("server asked %d times, want a refresh attempt after the first download", n)
+	}
+
+	// Without a cached copy the failure is reported
+	if _, err := NewIcecast(srv.URL + "/other.xml").Categories(context.Background()); err == nil {
+		t.Error("no error for unreachable directory without cache")
+	}
+}
@@ -0,0 +1,150 @@
+// Package provider abstracts station directories behind one interface
+// so the drums can browse Radio Browser and other sources alike
+package provider
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"crr/internal/data"
+)
+
+// StationProvider is a directory of stations
+type StationProvider interface {
+	// Name identifies provider in station Source and logs
+	Name() string
+
+	// Categories returns countries, tags and languages to browse
+	// (lists the provider does not know are empty)
+	Categories(ctx context.Context) (data.Directory, error)
+
+	// States returns states and regions of country (by name)
+	States(ctx context.Context, country string) ([]data.Category, error)
+
+	// Stations returns page (0-based) of stations matching q, sorted and filtered by f
+	// more reports whether the next page may have stations
+	Stations(ctx context.Context, q StationQuery, page int, f Filter) (stations []data.Station, more bool, err error)
+
+	// Search returns stations matching query in the whole directory
+	Search(ctx context.Context, q SearchQuery, f Filter) ([]data.Station, error)
+
+	// Resolve returns the stream URL to play for station
+	Resolve(ctx context.Context, st data.Station) (string, error)
+}
+
+// Names lists providers that can be opened by name
+var Names = []string{"radiobrowser", "icecast"}
+
+// Open returns provider merging directories named in names, in order
+// (nil: Radio Browser)
+func Open(names []string) (StationProvider, error) {
+	if len(names) == 0 {
+		return RadioBrowser{}, nil
+	}
+	var providers []StationProvider
+	var errs []string
+	used := map[string]bool{}
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if used[key] {
+			errs = append(errs, fmt.Sprintf("providers: %s is used twice", key))
+			continue
+		}
+		used[key] = true
+		switch key {
+		case "radiobrowser":
+			providers = append(providers, RadioBrowser{})
+		case "icecast":
+			providers = append(providers, NewIcecast(IcecastURL))
+		default:
+			errs = append(errs, fmt.Sprintf("providers: unknown provider %q (use %s)", name, strings.Join(Names, ", ")))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return New(providers...), nil
+}
+
+// userAgent is sent with directory and playlist requests
+const userAgent = "crr/1.0"
+
+// playlistLimit caps the size of a playlist read by ResolveLink
+const playlistLimit = 64 << 10
+
+// ResolveLink returns the first stream of a playlist link (.pls, .m3u),
+// other links are returned as they are
+func ResolveLink(ctx context.Context, link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if ext != ".pls" && ext != ".m3u" {
+		return link, nil
+	}
+
+	body, err := get(ctx, link)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	sc := bufio.NewScanner(io.LimitReader(body, playlistLimit))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if ext == ".pls" {
+			// File1=http://...
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || !strings.HasPrefix(strings.ToLower(key), "file") {
+				continue
+			}
+			line = strings.TrimSpace(value)
+		}
+		if strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+			return line, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s: no stream in playlist", link)
+}
+
+// get requests url and returns response body (caller closes it)
+func get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: unexpected status %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// StreamKey returns stream URL in a form that is equal for the same stream
+// (scheme and host in lower case, no trailing slash or fragment)
+func StreamKey(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return strings.TrimSpace(link)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	return u.String()
+}
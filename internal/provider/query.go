@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StationLimit is the number of stations per page of a drum selection
+var StationLimit = 20
+
+// SearchLimit is the number of stations returned by a global search
+var SearchLimit = 100
+
+// TagLimit is the number of most used tags offered in the Genre drum
+var TagLimit = 200
+
+// StationQuery narrows station lists to the selection of the drums
+type StationQuery struct {
+	CountryCode string // ISO 3166-1 country code
+	State       string // State or region (within country)
+	Language    string // Language
+	Tag         string // Tag (genre)
+}
+
+// IsEmpty reports whether query matches every station
+func (q StationQuery) IsEmpty() bool {
+	return strings.TrimSpace(q.CountryCode) == "" && strings.TrimSpace(q.State) == "" &&
+		strings.TrimSpace(q.Language) == "" && strings.TrimSpace(q.Tag) == ""
+}
+
+// Key returns short form for cache file names and lookups
+// ("country_tag", followed by state and language when set)
+func (q StationQuery) Key() string {
+	key := strings.ToLower(strings.TrimSpace(q.CountryCode)) + "_" + strings.ToLower(strings.TrimSpace(q.Tag))
+	if state := strings.TrimSpace(q.State); state != "" {
+		key += "_s-" + strings.ToLower(state)
+	}
+	if lang := strings.TrimSpace(q.Language); lang != "" {
+		key += "_l-" + strings.ToLower(lang)
+	}
+	return key
+}
+
+// SearchQuery describes a station search across the whole directory
+type SearchQuery struct {
+	Name     string // Part of station name
+	Tag      string // Tag (genre), optional
+	Language string // Language, optional
+}
+
+// IsEmpty reports whether query has nothing to search for
+func (q SearchQuery) IsEmpty() bool {
+	return strings.TrimSpace(q.Name) == "" && strings.TrimSpace(q.Tag) == "" && strings.TrimSpace(q.Language) == ""
+}
+
+// Sort orders of station lists
+const (
+	SortClicks  = "clicks"  // Most clicked first (default)
+	SortVotes   = "votes"   // Most voted first
+	SortName    = "name"    // Alphabetical
+	SortBitrate = "bitrate" // Highest bitrate first
+	SortChanged = "changed" // Recently changed first
+)
+
+// SortOrders lists sort orders in cycle order
+var SortOrders = []string{SortClicks, SortVotes, SortName, SortBitrate, SortChanged}
+
+// Filter is sort order and quality filters applied to station requests
+// The zero value is the default: most clicked, no filters
+type Filter struct {
+	Sort       string // One of SortOrders (empty: SortClicks)
+	Codec      string // Codec, e.g. MP3 or AAC (empty: any)
+	MinBitrate int    // Lowest bitrate in kbps (0: any)
+	HTTPSOnly  bool   // Only streams served over https
+	Language   string // Language, e.g. german (empty: any)
+}
+
+// IsZero reports whether filter is the default
+func (f Filter) IsZero() bool {
+	return f == Filter{} || f == Filter{Sort: SortClicks}
+}
+
+// ParseSort returns sort order by name
+func ParseSort(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, s := range SortOrders {
+		if s == name {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort %q (use %s)", name, strings.Join(SortOrders, ", "))
+}
+
+// String describes non-default settings for titles ("votes, mp3, 128k+, https")
+func (f Filter) String() string {
+	var parts []string
+	if f.Sort != "" && f.Sort != SortClicks {
+		parts = append(parts, "by "+f.Sort)
+	}
+	if f.Codec != "" {
+		parts = append(parts, strings.ToLower(f.Codec))
+	}
+	if f.MinBitrate > 0 {
+		parts = append(parts, strconv.Itoa(f.MinBitrate)+"k+")
+	}
+	if f.HTTPSOnly {
+		parts = append(parts, "https")
+	}
+	if f.Language != "" {
+		parts = append(parts, strings.ToLower(f.Language))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Key returns short form for cache file names ("" for the default filter)
+func (f Filter) Key() string {
+	if f.IsZero() {
+		return ""
+	}
+	https := ""
+	if f.HTTPSOnly {
+		https = "s"
+	}
+	return strings.ToLower(fmt.Sprintf("%s-%s-%d-%s-%s", f.Sort, f.Codec, f.MinBitrate, https, f.Language))
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"crr/internal/client"
+	"crr/internal/data"
+
+	rb "github.com/randomtoy/radiobrowser-go"
+)
+
+// RadioBrowser lists stations of the Radio Browser directory (see client)
+type RadioBrowser struct{}
+
+// Name returns "radiobrowser"
+func (RadioBrowser) Name() string {
+	return "radiobrowser"
+}
+
+// Categories returns live countries, tags and languages
+func (RadioBrowser) Categories(ctx context.Context) (data.Directory, error) {
+	countries, err := client.GetCountries(ctx)
+	var tags, languages []data.Category
+	if err == nil {
+		tags, err = client.GetTags(ctx, TagLimit)
+	}
+	if err == nil {
+		languages, err = client.GetLanguages(ctx)
+	}
+	if err == nil && (len(countries) == 0 || len(tags) == 0) {
+		err = errors.New("empty lists")
+	}
+	if err != nil {
+		return data.Directory{}, err
+	}
+	return data.Directory{Countries: countries, Tags: tags, Languages: languages}, nil
+}
+
+// States returns states and regions of country
+func (RadioBrowser) States(ctx context.Context, country string) ([]data.Category, error) {
+	return client.GetStates(ctx, country)
+}
+
+// Stations returns page of stations matching q
+func (p RadioBrowser) Stations(ctx context.Context, q StationQuery, page int, f Filter) ([]data.Station, bool, error) {
+	if q.IsEmpty() {
+		return nil, false, errors.New("station query is empty")
+	}
+	opts := searchOptions(f, q.Language)
+	opts.CountryCode = strings.TrimSpace(q.CountryCode)
+	opts.State = strings.TrimSpace(q.State)
+	opts.Tag = strings.ToLower(strings.TrimSpace(q.Tag))
+	opts.Offset, opts.Limit = page*StationLimit, StationLimit
+	stations, more, err := client.SearchStations(ctx, opts)
+	return p.tag(stations), more, err
+}
+
+// Search returns stations matching query in all countries
+func (p RadioBrowser) Search(ctx context.Context, q SearchQuery, f Filter) ([]data.Station, error) {
+	if q.IsEmpty() {
+		return nil, errors.New("search query is empty")
+	}
+	opts := searchOptions(f, q.Language)
+	opts.Name = strings.TrimSpace(q.Name)
+	opts.Tag = strings.ToLower(strings.TrimSpace(q.Tag))
+	opts.Limit = SearchLimit
+	stations, _, err := client.SearchStations(ctx, opts)
+	return p.tag(stations), err
+}
+
+// searchOptions returns Radio Browser request sorted and filtered by f
+// (language of the query wins over the one of the filter)
+func searchOptions(f Filter, language string) rb.StationSearchOptions {
+	var opts rb.StationSearchOptions
+	switch f.Sort {
+	case SortVotes:
+		opts.Order, opts.Reverse = rb.StationOrderVotes, true
+	case SortName:
+		opts.Order, opts.Reverse = rb.StationOrderName, false
+	case SortBitrate:
+		opts.Order, opts.Reverse = rb.StationOrderBitrate, true
+	case SortChanged:
+		opts.Order, opts.Reverse = rb.StationOrderChangeTS, true
+	default:
+		opts.Order, opts.Reverse = rb.StationOrderClickCount, true
+	}
+	opts.Codec = strings.TrimSpace(f.Codec)
+	opts.BitrateMin = f.MinBitrate
+	if f.HTTPSOnly {
+		https := true
+		opts.IsHTTPS = &https
+	}
+	if strings.TrimSpace(language) == "" {
+		language = f.Language
+	}
+	opts.Language = strings.ToLower(strings.TrimSpace(language))
+	return opts
+}
+
+// Resolve returns current stream URL of station (looked up again by UUID
+// as stored links may be outdated), expanding playlists
+func (RadioBrowser) Resolve(ctx context.Context, st data.Station) (string, error) {
+	link := st.Link
+	if st.UUID != "" {
+		if cur, err := client.GetStation(ctx, st.UUID); err == nil {
+			link = cur.Link
+		}
+	}
+	return ResolveLink(ctx, link)
+}
+
+// tag marks stations as listed by provider
+func (p RadioBrowser) tag(stations []data.Station) []data.Station {
+	for i := range stations {
+		stations[i].Source = p.Name()
+	}
+	return stations
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+
+	rb "github.com/randomtoy/radiobrowser-go"
+)
+
+func TestSearchOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   Filter
+		language string // Language of the query
+		order    rb.StationOrder
+		reverse  bool
+		lang     string
+	}{
+		{"default", Filter{}, "", rb.StationOrderClickCount, true, ""},
+		{"by name", Filter{Sort: SortName}, "", rb.StationOrderName, false, ""},
+		{"filter language", Filter{Sort: SortVotes, Language: " German"}, "", rb.StationOrderVotes, true, "german"},
+		{"query language wins", Filter{Language: "german"}, "French", rb.StationOrderClickCount, true, "french"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := searchOptions(tt.filter, tt.language)
+			if opts.Order != tt.order || opts.Reverse != tt.reverse || opts.Language != tt.lang {
+				t.Errorf("order %q reverse %v language %q, want %q %v %q",
+					opts.Order, opts.Reverse, opts.Language, tt.order, tt.reverse, tt.lang)
+			}
+		})
+	}
+
+	opts := searchOptions(Filter{Codec: " MP3 ", MinBitrate: 128, HTTPSOnly: true}, "")
+	if opts.Codec != "MP3" || opts.BitrateMin != 128 || opts.IsHTTPS == nil || !*opts.IsHTTPS {
+		t.Errorf("quality filters: codec %q, min bitrate %d, https %v", opts.Codec, opts.BitrateMin, opts.IsHTTPS)
+	}
+}
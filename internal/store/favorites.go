@@ -0,0 +1,41 @@
+package store
+
+import "crr/internal/data"
+
+// favoritesFile is the name of the favorites file
+const favoritesFile = "favorites.json"
+
+// Favorite is a starred station
+type Favorite struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	UUID    string `json:"uuid,omitempty"`
+	Country string `json:"country,omitempty"` // Country code the station was found under
+	Genre   string `json:"genre,omitempty"`   // Genre the station was found under
+}
+
+// NewFavorite creates favorite from station found under country code and genre
+func NewFavorite(s data.Station, country, genre string) Favorite {
+	return Favorite{Name: s.Name, URL: s.Link, UUID: s.UUID, Country: country, Genre: genre}
+}
+
+// Station returns favorite as a playable station
+func (f Favorite) Station() data.Station {
+	s := data.Station{Name: f.Name, Link: f.URL, UUID: f.UUID, CountryCode: f.Country}
+	if f.Genre != "" {
+		s.Tags = []string{f.Genre}
+	}
+	return s
+}
+
+// LoadFavorites reads saved favorites (empty list if none)
+func LoadFavorites() ([]Favorite, error) {
+	var favs []Favorite
+	err := load(favoritesFile, &favs)
+	return favs, err
+}
+
+// SaveFavorites writes favorites
+func SaveFavorites(favs []Favorite) error {
+	return save(favoritesFile, favs)
+}
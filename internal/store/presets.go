@@ -0,0 +1,26 @@
+package store
+
+// presetsFile is the name of the presets file
+const presetsFile = "presets.json"
+
+// PresetSlots is the number of memory presets (keys 1-9)
+const PresetSlots = 9
+
+// Presets are car-radio memory slots, slot i is bound to key i+1
+// An empty URL means the slot is free
+type Presets [PresetSlots]Favorite
+
+// LoadPresets reads saved presets (all free if none)
+func LoadPresets() (Presets, error) {
+	var slots []Favorite
+	err := load(presetsFile, &slots)
+
+	var p Presets
+	copy(p[:], slots)
+	return p, err
+}
+
+// SavePresets writes presets
+func SavePresets(p Presets) error {
+	return save(presetsFile, p[:])
+}
@@ -0,0 +1,30 @@
+package store
+
+// sessionFile is the name of the session file
+const sessionFile = "session.json"
+
+// Session is the UI state restored on the next launch
+type Session struct {
+	Active      int               `json:"active"`               // Active column
+	Selections  map[string]string `json:"selections,omitempty"` // Selected entry per column kind
+	Country     string            `json:"country,omitempty"`    // Selected entry of the first drum (older versions)
+	Genre       string            `json:"genre,omitempty"`      // Selected genre (older versions)
+	StationURL  string            `json:"station_url"`          // Last played stream
+	StationName string            `json:"station_name"`         // Name of last played station
+	Volume      int               `json:"volume"`               // Volume level (0-100)
+	Muted       bool              `json:"muted"`                // Whether sound was muted
+}
+
+// LoadSession reads saved session (ok is false on first launch)
+func LoadSession() (s Session, ok bool, err error) {
+	s.Volume = -1 // Marks missing file
+	if err := load(sessionFile, &s); err != nil {
+		return Session{}, false, err
+	}
+	return s, s.Volume >= 0, nil
+}
+
+// SaveSession writes session
+func SaveSession(s Session) error {
+	return save(sessionFile, s)
+}
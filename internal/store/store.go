@@ -0,0 +1,55 @@
+// Package store keeps user data (favorites, presets, session)
+// as JSON files under the XDG config directory
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"crr/internal/fsutil"
+)
+
+// Dir returns crr config directory ($XDG_CONFIG_HOME/crr), creating it
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "crr")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// load reads JSON file name into v
+// A missing file is not an error, v is left unchanged
+func load(name string, v any) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// save writes v as JSON file name atomically
+func save(name string, v any) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(filepath.Join(dir, name), append(b, '\n'))
+}
@@ -28,7 +28,7 @@ func RenderBoxWithTitle(content, title string, width int, borderColor lipgloss.C
 	titleStyle := lipgloss.NewStyle().Foreground(titleColor).Bold(true)
 
 	// Build top border with title
-	titleText := " " + title + " "
+	titleText := " " + Truncate(title, innerWidth-4) + " " // Long titles must not widen the box
 	titleLen := runewidth.StringWidth(titleText)
 
 	// Number of ─ characters left and right of title
@@ -3,9 +3,9 @@ package ui
 
 import "github.com/charmbracelet/lipgloss"
 
-// VisibleItems is the number of visible items in a drum
+// VisibleItems is the number of visible items in a drum (odd, set from config)
 // Selected item is always in center (index 2 for 5 items)
-const VisibleItems = 5
+var VisibleItems = 5
 
 // UI Colors
 var (
@@ -2,18 +2,169 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
+	"crr/internal/cache"
+	"crr/internal/client"
+	"crr/internal/config"
+	"crr/internal/logger"
 	"crr/internal/model"
+	"crr/internal/player"
+	"crr/internal/provider"
+	"crr/internal/store"
+	"crr/internal/ui"
 )
 
 func main() {
-	p := tea.NewProgram(model.NewDrums(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	cfg, err := loadConfig()
+	if err != nil {
+		exitConfigError(err)
+	}
+	applyConfig(cfg)
+	keys, err := model.NewKeyMap(cfg.KeyOverrides())
+	if err != nil {
+		exitConfigError(err)
+	}
+	columns, err := model.NewColumns(cfg.Columns)
+	if err != nil {
+		exitConfigError(err)
+	}
+	source, err := provider.Open(cfg.Providers)
+	if err != nil {
+		exitConfigError(err)
+	}
+	model.Provider = source
+	if name := source.Name(); name != (provider.RadioBrowser{}).Name() {
+		cache.Namespace = name // Lists of other directories are cached apart
+	}
+
+	backend, err := player.NewBackend(cfg.Backend)
+	if err != nil {
 		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
+
+	// Extract embedded chunks to temp directory
+	chunksDir, err := player.ExtractChunks()
+	if err != nil {
+		chunksDir = "chunks" // Fallback to local directory
+	}
+
+	pl := player.New(backend, chunksDir)
+	defer player.CleanupChunks()
+	defer pl.Cleanup()
+
+	// Own signal handling: SIGHUP is not handled by Bubble Tea, and all
+	// signals must end in the same teardown (deferred above)
+	p := tea.NewProgram(model.NewDrums(pl, keys, columns), tea.WithAltScreen(), tea.WithoutSignalHandler())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		<-signals
+		p.Quit()
+	}()
+
+	final, err := p.Run()
+	if err != nil {
+		fmt.Println("Error:", err)
+		pl.Cleanup()
+		player.CleanupChunks()
+		os.Exit(1)
+	}
+
+	// Remember drums, station and volume for the next launch
+	// (Update returns Drums by value, the initial model is a pointer)
+	var d *model.Drums
+	switch m := final.(type) {
+	case model.Drums:
+		d = &m
+	case *model.Drums:
+		d = m
+	}
+	if d != nil {
+		if err := store.SaveSession(d.Session()); err != nil {
+			fmt.Println("Error saving session:", err)
+		}
+	}
+}
+
+// loadConfig merges defaults, config file, CRR_* environment and flags
+func loadConfig() (config.Config, error) {
+	configPath := flag.String("config", envOr("CRR_CONFIG", config.Path()), "config file (YAML)")
+	for _, st := range config.Settings {
+		flag.String(st.Key, "", st.Usage+" (env "+st.Env()+")")
+	}
+	flag.Parse()
+
+	cfg := config.Default()
+	if err := cfg.LoadFile(*configPath); err != nil {
+		return cfg, err
+	}
+	if err := cfg.LoadEnv(); err != nil {
+		return cfg, err
+	}
+	var errs []error
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			if err := cfg.Set(f.Name, f.Value.String()); err != nil {
+				errs = append(errs, fmt.Errorf("-%w", err))
+			}
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return cfg, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// exitConfigError prints configuration problems and exits
+func exitConfigError(err error) {
+	fmt.Fprintln(os.Stderr, "Config error:")
+	fmt.Fprintln(os.Stderr, "  "+strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	os.Exit(2)
+}
+
+// applyConfig hands settings to the packages that use them
+func applyConfig(cfg config.Config) {
+	if err := logger.Open(cfg.LogFile); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: log file:", err)
+	}
+	player.SinkSpec = cfg.Sink
+	player.ChunkVolumeDB = strconv.FormatFloat(cfg.ChunkVolumeDB, 'f', -1, 64)
+	for _, m := range cfg.Mirrors {
+		client.Mirrors = append(client.Mirrors, strings.TrimRight(m, "/"))
+	}
+	provider.StationLimit = cfg.StationLimit
+	cache.StationsTTL = cfg.StationTTL
+	cache.DirectoryTTL = cfg.DirectoryTTL
+	cache.ListingTTL = cfg.IcecastTTL
+	provider.IcecastURL = cfg.IcecastURL
+	provider.TagLimit = cfg.TagLimit
+	model.DirectoryOrder = cfg.DirectoryOrder
+	model.FetchDebounceInterval = cfg.FetchDebounce
+	model.TickInterval = cfg.MarqueeTick
+	ui.VisibleItems = cfg.VisibleItems
+	ui.ActiveColor = lipgloss.Color(cfg.ActiveColor)
+	ui.InactiveColor = lipgloss.Color(cfg.InactiveColor)
+}
+
+// envOr returns environment variable or fallback if unset
+func envOr(name, fallback string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	return fallback
 }